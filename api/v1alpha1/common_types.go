@@ -2,25 +2,83 @@ package v1alpha1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // KubernetesConfig will be the JSON struct for Basic MongoDB Config
 type KubernetesConfig struct {
-	Image             string                       `json:"image"`
-	ImagePullPolicy   corev1.PullPolicy            `json:"imagePullPolicy,omitempty"`
-	Resources         *corev1.ResourceRequirements `json:"resources,omitempty"`
-	ImagePullSecret   *string                      `json:"imagePullSecret,omitempty"`
-	NodeSelector      map[string]string            `json:"nodeSelector,omitempty"`
-	Affinity          *corev1.Affinity             `json:"mongoAffinity,omitempty"`
-	Tolerations       *[]corev1.Toleration         `json:"tolerations,omitempty"`
-	PriorityClassName string                       `json:"priorityClassName,omitempty"`
-	SecurityContext   *corev1.PodSecurityContext   `json:"securityContext,omitempty"`
+	Image              string                       `json:"image"`
+	ImagePullPolicy    corev1.PullPolicy            `json:"imagePullPolicy,omitempty"`
+	Resources          *corev1.ResourceRequirements `json:"resources,omitempty"`
+	ImagePullSecret    *string                      `json:"imagePullSecret,omitempty"`
+	NodeSelector       map[string]string            `json:"nodeSelector,omitempty"`
+	Affinity           *corev1.Affinity             `json:"mongoAffinity,omitempty"`
+	Tolerations        *[]corev1.Toleration         `json:"tolerations,omitempty"`
+	PriorityClassName  string                       `json:"priorityClassName,omitempty"`
+	SecurityContext    *corev1.PodSecurityContext   `json:"securityContext,omitempty"`
+	LivenessProbe      *corev1.Probe                `json:"livenessProbe,omitempty"`
+	ReadinessProbe     *corev1.Probe                `json:"readinessProbe,omitempty"`
+	StartupProbe       *corev1.Probe                `json:"startupProbe,omitempty"`
+	Sidecars           []corev1.Container           `json:"sidecars,omitempty"`
+	InitContainers     []corev1.Container           `json:"initContainers,omitempty"`
+	ExtraVolumes       []corev1.Volume              `json:"extraVolumes,omitempty"`
+	ExtraVolumeMounts  []corev1.VolumeMount         `json:"extraVolumeMounts,omitempty"`
+	ExtraEnv           []corev1.EnvVar              `json:"extraEnv,omitempty"`
+	PodLabels          map[string]string            `json:"podLabels,omitempty"`
+	PodAnnotations     map[string]string            `json:"podAnnotations,omitempty"`
+	ServiceAccountName string                       `json:"serviceAccountName,omitempty"`
+	// ContainerSecurityContext is the security context of the mongo container
+	ContainerSecurityContext *corev1.SecurityContext `json:"containerSecurityContext,omitempty"`
+	// ImageFlavor selects how the mongod container is started, official and percona images get their mongod arguments and root user from the operator
+	// +kubebuilder:validation:Enum=opstree;official;percona
+	ImageFlavor string `json:"imageFlavor,omitempty"`
+	// TerminationGracePeriodSeconds is the time given to the preStop hook and mongod to step down and shut down, 60 seconds by default
+	// +kubebuilder:validation:Minimum=0
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
 }
 
 // MongoDBSecurity is the JSON struct for MongoDB security configuration
 type MongoDBSecurity struct {
-	MongoDBAdminUser string                 `json:"mongoDBAdminUser"`
-	SecretRef        ExistingPasswordSecret `json:"secretRef"`
+	MongoDBAdminUser string `json:"mongoDBAdminUser"`
+	// SecretRef is the secret holding the admin password, it is generated when no name is set or the secret does not exist
+	SecretRef ExistingPasswordSecret `json:"secretRef,omitempty"`
+	// PasswordGenerator configures the admin password generated by the operator
+	PasswordGenerator *PasswordGenerator `json:"passwordGenerator,omitempty"`
+	// Vault is where the operator and the pods read the admin password from, no admin secret is generated
+	Vault *VaultCredentialSource `json:"vault,omitempty"`
+}
+
+// VaultCredentialSource is the JSON struct for a password stored in HashiCorp Vault
+type VaultCredentialSource struct {
+	// Address is the address of the Vault server, the VAULT_ADDR of the operator is used by default
+	Address string `json:"address,omitempty"`
+	// Role is the Kubernetes auth role of the operator service account, the VAULT_TOKEN of the operator is used without a role
+	Role string `json:"role,omitempty"`
+	// PodRole is the Kubernetes auth role of the MongoDB pods, the Vault Agent injector renders the password in the pods with it, role by default
+	PodRole string `json:"podRole,omitempty"`
+	// AuthMount is the mount path of the Kubernetes auth method, kubernetes by default
+	AuthMount string `json:"authMount,omitempty"`
+	// Engine is the secrets engine holding the password, kv-v2 by default
+	// +kubebuilder:validation:Enum=kv;kv-v2;database
+	Engine string `json:"engine,omitempty"`
+	// Mount is the mount path of the secrets engine
+	Mount string `json:"mount"`
+	// Path is the path of the secret in a kv engine, or the static role in a database engine
+	Path string `json:"path"`
+	// Key is the key of the password in a kv secret, password by default
+	Key string `json:"key,omitempty"`
+	// FallbackToSecret keeps the secretRef secret for the pods, the operator reads it while Vault does not hold the password
+	FallbackToSecret bool `json:"fallbackToSecret,omitempty"`
+}
+
+// PasswordGenerator is the JSON struct for a password generated by the operator
+type PasswordGenerator struct {
+	// +kubebuilder:validation:Minimum=12
+	// +kubebuilder:validation:Maximum=128
+	Length *int32 `json:"length,omitempty"`
+	// Charset is the characters the password is made of, alphanumeric by default, only characters allowed unescaped in connection strings can be used
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9._~-]+$`
+	Charset string `json:"charset,omitempty"`
 }
 
 // MongoDBMonitoring is the JSON struct for monitoring MongoDB
@@ -29,6 +87,8 @@ type MongoDBMonitoring struct {
 	Image           string                       `json:"image"`
 	ImagePullPolicy corev1.PullPolicy            `json:"imagePullPolicy,omitempty"`
 	Resources       *corev1.ResourceRequirements `json:"resources,omitempty"`
+	LivenessProbe   *corev1.Probe                `json:"livenessProbe,omitempty"`
+	ReadinessProbe  *corev1.Probe                `json:"readinessProbe,omitempty"`
 }
 
 // ExistingPasswordSecret is the struct to access the existing secret
@@ -43,3 +103,88 @@ type Storage struct {
 	StorageClassName *string                             `json:"storageClass,omitempty" protobuf:"bytes,5,opt,name=storageClassName"`
 	StorageSize      string                              `json:"storageSize,omitempty" protobuf:"bytes,5,opt,name=storageClassName"`
 }
+
+// MongodConfig is the JSON struct for mongod configuration rendered by the operator
+type MongodConfig struct {
+	Storage            *MongodStorageConfig            `json:"storage,omitempty"`
+	Net                *MongodNetConfig                `json:"net,omitempty"`
+	OperationProfiling *MongodOperationProfilingConfig `json:"operationProfiling,omitempty"`
+	SetParameter       map[string]string               `json:"setParameter,omitempty"`
+}
+
+// MongodStorageConfig is the JSON struct for mongod storage configuration
+type MongodStorageConfig struct {
+	DirectoryPerDB *bool                   `json:"directoryPerDB,omitempty"`
+	WiredTiger     *MongodWiredTigerConfig `json:"wiredTiger,omitempty"`
+}
+
+// MongodWiredTigerConfig is the JSON struct for mongod WiredTiger configuration
+type MongodWiredTigerConfig struct {
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	CacheSizeGB string `json:"cacheSizeGB,omitempty"`
+	// +kubebuilder:validation:Enum=none;snappy;zlib;zstd
+	BlockCompressor string `json:"blockCompressor,omitempty"`
+	// CacheSizeRatio is applied to the container memory limit minus 1GB, as mongod does with the host memory, when cacheSizeGB is not set
+	// +kubebuilder:validation:Pattern=`^(0(\.[0-9]+)?|1(\.0+)?)$`
+	CacheSizeRatio string `json:"cacheSizeRatio,omitempty"`
+}
+
+// MongodNetConfig is the JSON struct for mongod network configuration
+type MongodNetConfig struct {
+	// +kubebuilder:validation:Minimum=1
+	MaxIncomingConnections *int32 `json:"maxIncomingConnections,omitempty"`
+	// +kubebuilder:validation:Pattern=`^(snappy|zlib|zstd|disabled)(,(snappy|zlib|zstd))*$`
+	Compressors string `json:"compressors,omitempty"`
+}
+
+// MongodOperationProfilingConfig is the JSON struct for mongod profiler configuration
+type MongodOperationProfilingConfig struct {
+	// +kubebuilder:validation:Enum=off;slowOp;all
+	Mode string `json:"mode,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	SlowOpThresholdMs *int32 `json:"slowOpThresholdMs,omitempty"`
+	// +kubebuilder:validation:Pattern=`^(0(\.[0-9]+)?|1(\.0+)?)$`
+	SlowOpSampleRate string `json:"slowOpSampleRate,omitempty"`
+}
+
+// ObjectMetadata is the JSON struct for user defined labels and annotations
+type ObjectMetadata struct {
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// MetadataOverrides is the JSON struct for labels and annotations per generated object type
+type MetadataOverrides struct {
+	Service     *ObjectMetadata `json:"service,omitempty"`
+	StatefulSet *ObjectMetadata `json:"statefulSet,omitempty"`
+	Pod         *ObjectMetadata `json:"pod,omitempty"`
+	PVC         *ObjectMetadata `json:"pvc,omitempty"`
+}
+
+// MongoDBService is the JSON struct for the client Service of MongoDB
+type MongoDBService struct {
+	// +kubebuilder:validation:Enum=ClusterIP;NodePort;LoadBalancer
+	Type corev1.ServiceType `json:"type,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port *int32 `json:"port,omitempty"`
+	// +kubebuilder:validation:Minimum=30000
+	// +kubebuilder:validation:Maximum=32767
+	NodePort                 *int32            `json:"nodePort,omitempty"`
+	Annotations              map[string]string `json:"annotations,omitempty"`
+	LoadBalancerSourceRanges []string          `json:"loadBalancerSourceRanges,omitempty"`
+	// +kubebuilder:validation:Enum=Cluster;Local
+	ExternalTrafficPolicy corev1.ServiceExternalTrafficPolicyType `json:"externalTrafficPolicy,omitempty"`
+}
+
+// MongoDBHibernationSchedule defines the cron schedules, in UTC, to hibernate and wake up MongoDB, the latest scheduled action wins
+type MongoDBHibernationSchedule struct {
+	Hibernate string `json:"hibernate"`
+	Wake      string `json:"wake"`
+}
+
+// MongoDBHibernationStatus defines the hibernation state of MongoDB
+type MongoDBHibernationStatus struct {
+	Phase              string       `json:"phase,omitempty"`
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
+}
@@ -27,10 +27,22 @@ type MongoDBSpec struct {
 	MongoDBSecurity         *MongoDBSecurity   `json:"mongoDBSecurity"`
 	MongoDBMonitoring       *MongoDBMonitoring `json:"mongoDBMonitoring,omitempty"`
 	MongoDBAdditionalConfig *string            `json:"mongoDBAdditionalConfig,omitempty"`
+	MongodConfig            *MongodConfig      `json:"mongod,omitempty"`
+	CommonLabels            map[string]string  `json:"commonLabels,omitempty"`
+	CommonAnnotations       map[string]string  `json:"commonAnnotations,omitempty"`
+	MetadataOverrides       *MetadataOverrides `json:"metadataOverrides,omitempty"`
+	Service                 *MongoDBService    `json:"service,omitempty"`
+	// Paused stops the reconciliation of MongoDB, for example during a maintenance
+	Paused bool `json:"paused,omitempty"`
+	// Hibernate scales MongoDB down to zero while keeping its volumes
+	Hibernate           bool                        `json:"hibernate,omitempty"`
+	HibernationSchedule *MongoDBHibernationSchedule `json:"hibernationSchedule,omitempty"`
 }
 
 // MongoDBStatus defines the observed state of MongoDB
 type MongoDBStatus struct {
+	Hibernation *MongoDBHibernationStatus `json:"hibernation,omitempty"`
+	AdminSecret string                    `json:"adminSecret,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -30,6 +31,151 @@ type MongoDBClusterSpec struct {
 	MongoDBMonitoring       *MongoDBMonitoring          `json:"mongoDBMonitoring,omitempty"`
 	PodDisruptionBudget     *MongoDBPodDisruptionBudget `json:"podDisruptionBudget,omitempty"`
 	MongoDBAdditionalConfig *string                     `json:"mongoDBAdditionalConfig,omitempty"`
+	MongodConfig            *MongodConfig               `json:"mongod,omitempty"`
+	CommonLabels            map[string]string           `json:"commonLabels,omitempty"`
+	CommonAnnotations       map[string]string           `json:"commonAnnotations,omitempty"`
+	MetadataOverrides       *MetadataOverrides          `json:"metadataOverrides,omitempty"`
+	Service                 *MongoDBService             `json:"service,omitempty"`
+	PrimaryService          *MongoDBService             `json:"primaryService,omitempty"`
+	Members                 []MongoDBClusterMember      `json:"members,omitempty"`
+	TopologySpread          *MongoDBTopologySpread      `json:"topologySpread,omitempty"`
+	Backup                  *MongoDBBackup              `json:"backup,omitempty"`
+	Bootstrap               *MongoDBBootstrap           `json:"bootstrap,omitempty"`
+	DisasterRecovery        *MongoDBDisasterRecovery    `json:"disasterRecovery,omitempty"`
+	// Paused stops the reconciliation of MongoDB cluster, for example during a maintenance
+	Paused bool `json:"paused,omitempty"`
+	// Hibernate steps down the primary and scales MongoDB cluster down to zero while keeping its volumes
+	Hibernate           bool                        `json:"hibernate,omitempty"`
+	HibernationSchedule *MongoDBHibernationSchedule `json:"hibernationSchedule,omitempty"`
+	AutoResync          *MongoDBAutoResync          `json:"autoResync,omitempty"`
+}
+
+// MongoDBAutoResync defines the automatic initial sync of members which are too stale to catch up from the oplog
+type MongoDBAutoResync struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// MinIntervalMinutes is the minimum time between two automatic resyncs in the cluster, 60 minutes by default
+	// +kubebuilder:validation:Minimum=1
+	MinIntervalMinutes *int32 `json:"minIntervalMinutes,omitempty"`
+}
+
+// MongoDBDisasterRecovery defines the members of MongoDB cluster replica set running in another Kubernetes cluster
+type MongoDBDisasterRecovery struct {
+	// AdvertisedDomain replaces the service domain in the replica set member hosts, members are then <name>-cluster-<ordinal>.<advertisedDomain>:27017
+	AdvertisedDomain string `json:"advertisedDomain,omitempty"`
+	// ExternalMembers are added to the replica set without votes and priority
+	ExternalMembers []MongoDBExternalMember `json:"externalMembers,omitempty"`
+	// Standby runs the cluster as external members of a replica set managed by another MongoDB cluster until it is promoted
+	Standby   bool              `json:"standby,omitempty"`
+	Promotion *MongoDBPromotion `json:"promotion,omitempty"`
+}
+
+// MongoDBExternalMember defines a replica set member running in another Kubernetes cluster
+type MongoDBExternalMember struct {
+	// +kubebuilder:validation:Pattern=`^[^:]+:[0-9]+$`
+	Host string            `json:"host"`
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// MongoDBPromotion defines the promotion of a standby MongoDB cluster when the primary site is lost
+type MongoDBPromotion struct {
+	// Promote force reconfigures the surviving members into a new replica set majority
+	Promote bool `json:"promote,omitempty"`
+	// AutomaticAfterSeconds promotes the cluster when its members see no primary for this duration,
+	// and the primary site is fenced with the mongodb.opstreelabs.in/primary-site-fenced annotation
+	// +kubebuilder:validation:Minimum=30
+	AutomaticAfterSeconds *int32 `json:"automaticAfterSeconds,omitempty"`
+}
+
+// MongoDBBootstrap defines the source of the data loaded in a new MongoDB cluster, only one source can be defined
+type MongoDBBootstrap struct {
+	Backup             *BootstrapBackup             `json:"backup,omitempty"`
+	VolumeSnapshot     *BootstrapVolumeSnapshot     `json:"volumeSnapshot,omitempty"`
+	ExternalReplicaSet *BootstrapExternalReplicaSet `json:"externalReplicaSet,omitempty"`
+}
+
+// BootstrapBackup defines a backup archive of the object storage as bootstrap source
+type BootstrapBackup struct {
+	Storage BackupStorage `json:"storage"`
+	// Key is the object key of the archive, the latest base backup under the storage prefix is used when it is not set
+	Key string `json:"key,omitempty"`
+}
+
+// BootstrapVolumeSnapshot defines a VolumeSnapshot of a MongoDB member as bootstrap source
+type BootstrapVolumeSnapshot struct {
+	Name string `json:"name"`
+}
+
+// BootstrapExternalReplicaSet defines a live MongoDB replica set as bootstrap source
+type BootstrapExternalReplicaSet struct {
+	// URISecret is the secret key holding the connection string of the external replica set
+	URISecret corev1.SecretKeySelector `json:"uriSecret"`
+}
+
+// MongoDBBackup defines the backup configuration of MongoDB cluster
+type MongoDBBackup struct {
+	Storage  *BackupStorage         `json:"storage,omitempty"`
+	PITR     *MongoDBPITR           `json:"pitr,omitempty"`
+	Snapshot *MongoDBSnapshotBackup `json:"snapshot,omitempty"`
+}
+
+// MongoDBSnapshotBackup defines the VolumeSnapshot based physical backups of MongoDB cluster
+type MongoDBSnapshotBackup struct {
+	Enabled                 bool    `json:"enabled,omitempty"`
+	VolumeSnapshotClassName *string `json:"volumeSnapshotClassName,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	IntervalHours *int32 `json:"intervalHours,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	Retention *int32 `json:"retention,omitempty"`
+	// SeedNewMembers creates the volumes of new members from the latest snapshot, so they only catch up from the oplog
+	SeedNewMembers bool `json:"seedNewMembers,omitempty"`
+}
+
+// BackupStorage defines the S3 compatible object storage for MongoDB backups
+type BackupStorage struct {
+	Endpoint string `json:"endpoint"`
+	Bucket   string `json:"bucket"`
+	Prefix   string `json:"prefix,omitempty"`
+	Region   string `json:"region,omitempty"`
+	// CredentialsSecret is the name of the secret with accessKey and secretKey keys
+	CredentialsSecret string `json:"credentialsSecret"`
+}
+
+// MongoDBPITR defines the continuous oplog archiving of MongoDB cluster for point-in-time recovery
+type MongoDBPITR struct {
+	Enabled       bool   `json:"enabled,omitempty"`
+	Image         string `json:"image,omitempty"`
+	UploaderImage string `json:"uploaderImage,omitempty"`
+	// +kubebuilder:validation:Minimum=10
+	ChunkIntervalSeconds *int32 `json:"chunkIntervalSeconds,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	BaseBackupIntervalHours *int32                       `json:"baseBackupIntervalHours,omitempty"`
+	Resources               *corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// MongoDBTopologySpread defines the spreading of MongoDB cluster members across nodes and zones
+type MongoDBTopologySpread struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	MaxSkew *int32 `json:"maxSkew,omitempty"`
+	// +kubebuilder:validation:Enum=DoNotSchedule;ScheduleAnyway
+	WhenUnsatisfiable corev1.UnsatisfiableConstraintAction `json:"whenUnsatisfiable,omitempty"`
+	ZoneKey           string                               `json:"zoneKey,omitempty"`
+	ZoneTag           string                               `json:"zoneTag,omitempty"`
+}
+
+// MongoDBClusterMember defines the replica set configuration override for a MongoDB cluster member
+type MongoDBClusterMember struct {
+	// +kubebuilder:validation:Minimum=0
+	Ordinal int32 `json:"ordinal"`
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=1000
+	Priority *int32 `json:"priority,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=1
+	Votes              *int32            `json:"votes,omitempty"`
+	Hidden             bool              `json:"hidden,omitempty"`
+	SecondaryDelaySecs *int32            `json:"secondaryDelaySecs,omitempty"`
+	Tags               map[string]string `json:"tags,omitempty"`
 }
 
 // MongoDBPodDisruptionBudget defines the struct for MongoDB cluster
@@ -41,6 +187,51 @@ type MongoDBPodDisruptionBudget struct {
 
 // MongoDBClusterStatus defines the observed state of MongoDBCluster
 type MongoDBClusterStatus struct {
+	PITR             *MongoDBPITRStatus             `json:"pitr,omitempty"`
+	LatestSnapshot   *MongoDBSnapshotStatus         `json:"latestSnapshot,omitempty"`
+	PendingSnapshot  *MongoDBSnapshotStatus         `json:"pendingSnapshot,omitempty"`
+	Bootstrap        *MongoDBBootstrapStatus        `json:"bootstrap,omitempty"`
+	DisasterRecovery *MongoDBDisasterRecoveryStatus `json:"disasterRecovery,omitempty"`
+	Hibernation      *MongoDBHibernationStatus      `json:"hibernation,omitempty"`
+	AutoResync       *MongoDBAutoResyncStatus       `json:"autoResync,omitempty"`
+	AdminSecret      string                         `json:"adminSecret,omitempty"`
+}
+
+// MongoDBAutoResyncStatus defines the latest automatic resync of a stale MongoDB cluster member
+type MongoDBAutoResyncStatus struct {
+	Member         int32        `json:"member"`
+	Phase          string       `json:"phase,omitempty"`
+	StartTime      *metav1.Time `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// MongoDBDisasterRecoveryStatus defines the promotion state of a standby MongoDB cluster
+type MongoDBDisasterRecoveryStatus struct {
+	PrimaryLostSince *metav1.Time `json:"primaryLostSince,omitempty"`
+	PromotedAt       *metav1.Time `json:"promotedAt,omitempty"`
+	RemovedMembers   []string     `json:"removedMembers,omitempty"`
+}
+
+// MongoDBBootstrapStatus defines the state of the data loading in a new MongoDB cluster
+type MongoDBBootstrapStatus struct {
+	Phase   string `json:"phase,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// MongoDBSnapshotStatus defines a VolumeSnapshot backup of MongoDB cluster, a pending snapshot holds the fsync lock of its member until it is taken
+type MongoDBSnapshotStatus struct {
+	Name         string       `json:"name,omitempty"`
+	Member       string       `json:"member,omitempty"`
+	Optime       string       `json:"optime,omitempty"`
+	OptimeDate   *metav1.Time `json:"optimeDate,omitempty"`
+	CreationTime *metav1.Time `json:"creationTime,omitempty"`
+}
+
+// MongoDBPITRStatus defines the recoverable time window of MongoDB cluster
+type MongoDBPITRStatus struct {
+	RecoverableFrom  *metav1.Time `json:"recoverableFrom,omitempty"`
+	RecoverableTo    *metav1.Time `json:"recoverableTo,omitempty"`
+	LatestBaseBackup *metav1.Time `json:"latestBaseBackup,omitempty"`
 }
 
 //+kubebuilder:object:root=true
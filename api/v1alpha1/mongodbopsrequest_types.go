@@ -0,0 +1,88 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MongoDBOpsRequestSpec defines the desired state of MongoDBOpsRequest
+type MongoDBOpsRequestSpec struct {
+	// +kubebuilder:validation:Enum=StepDown;Restart;Resync;Compact;RotateLogs
+	Type        string                `json:"type"`
+	ClusterName string                `json:"clusterName"`
+	Parameters  *MongoDBOpsParameters `json:"parameters,omitempty"`
+}
+
+// MongoDBOpsParameters defines the parameters of a day-2 operation on MongoDB cluster
+type MongoDBOpsParameters struct {
+	// Member is the ordinal of the targeted member, it is required by Restart, Resync and Compact, RotateLogs targets all the members when it is not set
+	// +kubebuilder:validation:Minimum=0
+	Member *int32 `json:"member,omitempty"`
+	// StepDownSeconds is how long the stepped down primary cannot be elected again
+	// +kubebuilder:validation:Minimum=10
+	StepDownSeconds *int32 `json:"stepDownSeconds,omitempty"`
+	// Database and Collections are the collections compacted by Compact
+	Database    string   `json:"database,omitempty"`
+	Collections []string `json:"collections,omitempty"`
+}
+
+// MongoDBOpsRequestStatus defines the observed state of MongoDBOpsRequest
+type MongoDBOpsRequestStatus struct {
+	Phase          string       `json:"phase,omitempty"`
+	Message        string       `json:"message,omitempty"`
+	StartTime      *metav1.Time `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// History records every phase and message of the operation
+	History []MongoDBOpsRequestEvent `json:"history,omitempty"`
+}
+
+// MongoDBOpsRequestEvent defines a step of a day-2 operation
+type MongoDBOpsRequestEvent struct {
+	Time    metav1.Time `json:"time"`
+	Phase   string      `json:"phase"`
+	Message string      `json:"message,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`
+//+kubebuilder:printcolumn:name="Cluster",type=string,JSONPath=`.spec.clusterName`
+//+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// MongoDBOpsRequest is the Schema for the mongodbopsrequests API
+type MongoDBOpsRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MongoDBOpsRequestSpec   `json:"spec,omitempty"`
+	Status MongoDBOpsRequestStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// MongoDBOpsRequestList contains a list of MongoDBOpsRequest
+type MongoDBOpsRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MongoDBOpsRequest `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MongoDBOpsRequest{}, &MongoDBOpsRequestList{})
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MongoDBRestoreSpec defines the desired state of MongoDBRestore
+type MongoDBRestoreSpec struct {
+	ClusterName string `json:"clusterName"`
+	// TargetTime is the point in time to recover to, the latest archived oplog is replayed when it is not set
+	TargetTime *metav1.Time `json:"targetTime,omitempty"`
+}
+
+// MongoDBRestoreStatus defines the observed state of MongoDBRestore
+type MongoDBRestoreStatus struct {
+	Phase   string `json:"phase,omitempty"`
+	Message string `json:"message,omitempty"`
+	JobName string `json:"jobName,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Cluster",type=string,JSONPath=`.spec.clusterName`
+//+kubebuilder:printcolumn:name="Target",type=string,JSONPath=`.spec.targetTime`
+//+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+
+// MongoDBRestore is the Schema for the mongodbrestores API
+type MongoDBRestore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MongoDBRestoreSpec   `json:"spec,omitempty"`
+	Status MongoDBRestoreStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// MongoDBRestoreList contains a list of MongoDBRestore
+type MongoDBRestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MongoDBRestore `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MongoDBRestore{}, &MongoDBRestoreList{})
+}
@@ -26,6 +26,68 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupStorage) DeepCopyInto(out *BackupStorage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupStorage.
+func (in *BackupStorage) DeepCopy() *BackupStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BootstrapBackup) DeepCopyInto(out *BootstrapBackup) {
+	*out = *in
+	out.Storage = in.Storage
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootstrapBackup.
+func (in *BootstrapBackup) DeepCopy() *BootstrapBackup {
+	if in == nil {
+		return nil
+	}
+	out := new(BootstrapBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BootstrapExternalReplicaSet) DeepCopyInto(out *BootstrapExternalReplicaSet) {
+	*out = *in
+	in.URISecret.DeepCopyInto(&out.URISecret)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootstrapExternalReplicaSet.
+func (in *BootstrapExternalReplicaSet) DeepCopy() *BootstrapExternalReplicaSet {
+	if in == nil {
+		return nil
+	}
+	out := new(BootstrapExternalReplicaSet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BootstrapVolumeSnapshot) DeepCopyInto(out *BootstrapVolumeSnapshot) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootstrapVolumeSnapshot.
+func (in *BootstrapVolumeSnapshot) DeepCopy() *BootstrapVolumeSnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(BootstrapVolumeSnapshot)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExistingPasswordSecret) DeepCopyInto(out *ExistingPasswordSecret) {
 	*out = *in
@@ -92,6 +154,80 @@ func (in *KubernetesConfig) DeepCopyInto(out *KubernetesConfig) {
 		*out = new(v1.PodSecurityContext)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.LivenessProbe != nil {
+		in, out := &in.LivenessProbe, &out.LivenessProbe
+		*out = new(v1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReadinessProbe != nil {
+		in, out := &in.ReadinessProbe, &out.ReadinessProbe
+		*out = new(v1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StartupProbe != nil {
+		in, out := &in.StartupProbe, &out.StartupProbe
+		*out = new(v1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Sidecars != nil {
+		in, out := &in.Sidecars, &out.Sidecars
+		*out = make([]v1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.InitContainers != nil {
+		in, out := &in.InitContainers, &out.InitContainers
+		*out = make([]v1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExtraVolumes != nil {
+		in, out := &in.ExtraVolumes, &out.ExtraVolumes
+		*out = make([]v1.Volume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExtraVolumeMounts != nil {
+		in, out := &in.ExtraVolumeMounts, &out.ExtraVolumeMounts
+		*out = make([]v1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExtraEnv != nil {
+		in, out := &in.ExtraEnv, &out.ExtraEnv
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PodLabels != nil {
+		in, out := &in.PodLabels, &out.PodLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PodAnnotations != nil {
+		in, out := &in.PodAnnotations, &out.PodAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ContainerSecurityContext != nil {
+		in, out := &in.ContainerSecurityContext, &out.ContainerSecurityContext
+		*out = new(v1.SecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TerminationGracePeriodSeconds != nil {
+		in, out := &in.TerminationGracePeriodSeconds, &out.TerminationGracePeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubernetesConfig.
@@ -104,13 +240,48 @@ func (in *KubernetesConfig) DeepCopy() *KubernetesConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetadataOverrides) DeepCopyInto(out *MetadataOverrides) {
+	*out = *in
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(ObjectMetadata)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StatefulSet != nil {
+		in, out := &in.StatefulSet, &out.StatefulSet
+		*out = new(ObjectMetadata)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Pod != nil {
+		in, out := &in.Pod, &out.Pod
+		*out = new(ObjectMetadata)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PVC != nil {
+		in, out := &in.PVC, &out.PVC
+		*out = new(ObjectMetadata)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetadataOverrides.
+func (in *MetadataOverrides) DeepCopy() *MetadataOverrides {
+	if in == nil {
+		return nil
+	}
+	out := new(MetadataOverrides)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MongoDB) DeepCopyInto(out *MongoDB) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDB.
@@ -131,13 +302,131 @@ func (in *MongoDB) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBAutoResync) DeepCopyInto(out *MongoDBAutoResync) {
+	*out = *in
+	if in.MinIntervalMinutes != nil {
+		in, out := &in.MinIntervalMinutes, &out.MinIntervalMinutes
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBAutoResync.
+func (in *MongoDBAutoResync) DeepCopy() *MongoDBAutoResync {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBAutoResync)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBAutoResyncStatus) DeepCopyInto(out *MongoDBAutoResyncStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBAutoResyncStatus.
+func (in *MongoDBAutoResyncStatus) DeepCopy() *MongoDBAutoResyncStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBAutoResyncStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBBackup) DeepCopyInto(out *MongoDBBackup) {
+	*out = *in
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		*out = new(BackupStorage)
+		**out = **in
+	}
+	if in.PITR != nil {
+		in, out := &in.PITR, &out.PITR
+		*out = new(MongoDBPITR)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Snapshot != nil {
+		in, out := &in.Snapshot, &out.Snapshot
+		*out = new(MongoDBSnapshotBackup)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBBackup.
+func (in *MongoDBBackup) DeepCopy() *MongoDBBackup {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBBootstrap) DeepCopyInto(out *MongoDBBootstrap) {
+	*out = *in
+	if in.Backup != nil {
+		in, out := &in.Backup, &out.Backup
+		*out = new(BootstrapBackup)
+		**out = **in
+	}
+	if in.VolumeSnapshot != nil {
+		in, out := &in.VolumeSnapshot, &out.VolumeSnapshot
+		*out = new(BootstrapVolumeSnapshot)
+		**out = **in
+	}
+	if in.ExternalReplicaSet != nil {
+		in, out := &in.ExternalReplicaSet, &out.ExternalReplicaSet
+		*out = new(BootstrapExternalReplicaSet)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBBootstrap.
+func (in *MongoDBBootstrap) DeepCopy() *MongoDBBootstrap {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBBootstrap)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBBootstrapStatus) DeepCopyInto(out *MongoDBBootstrapStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBBootstrapStatus.
+func (in *MongoDBBootstrapStatus) DeepCopy() *MongoDBBootstrapStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBBootstrapStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MongoDBCluster) DeepCopyInto(out *MongoDBCluster) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBCluster.
@@ -190,6 +479,43 @@ func (in *MongoDBClusterList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBClusterMember) DeepCopyInto(out *MongoDBClusterMember) {
+	*out = *in
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Votes != nil {
+		in, out := &in.Votes, &out.Votes
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SecondaryDelaySecs != nil {
+		in, out := &in.SecondaryDelaySecs, &out.SecondaryDelaySecs
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBClusterMember.
+func (in *MongoDBClusterMember) DeepCopy() *MongoDBClusterMember {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBClusterMember)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MongoDBClusterSpec) DeepCopyInto(out *MongoDBClusterSpec) {
 	*out = *in
@@ -229,6 +555,77 @@ func (in *MongoDBClusterSpec) DeepCopyInto(out *MongoDBClusterSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.MongodConfig != nil {
+		in, out := &in.MongodConfig, &out.MongodConfig
+		*out = new(MongodConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CommonLabels != nil {
+		in, out := &in.CommonLabels, &out.CommonLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.CommonAnnotations != nil {
+		in, out := &in.CommonAnnotations, &out.CommonAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MetadataOverrides != nil {
+		in, out := &in.MetadataOverrides, &out.MetadataOverrides
+		*out = new(MetadataOverrides)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(MongoDBService)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PrimaryService != nil {
+		in, out := &in.PrimaryService, &out.PrimaryService
+		*out = new(MongoDBService)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]MongoDBClusterMember, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TopologySpread != nil {
+		in, out := &in.TopologySpread, &out.TopologySpread
+		*out = new(MongoDBTopologySpread)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Backup != nil {
+		in, out := &in.Backup, &out.Backup
+		*out = new(MongoDBBackup)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Bootstrap != nil {
+		in, out := &in.Bootstrap, &out.Bootstrap
+		*out = new(MongoDBBootstrap)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DisasterRecovery != nil {
+		in, out := &in.DisasterRecovery, &out.DisasterRecovery
+		*out = new(MongoDBDisasterRecovery)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HibernationSchedule != nil {
+		in, out := &in.HibernationSchedule, &out.HibernationSchedule
+		*out = new(MongoDBHibernationSchedule)
+		**out = **in
+	}
+	if in.AutoResync != nil {
+		in, out := &in.AutoResync, &out.AutoResync
+		*out = new(MongoDBAutoResync)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBClusterSpec.
@@ -244,6 +641,41 @@ func (in *MongoDBClusterSpec) DeepCopy() *MongoDBClusterSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MongoDBClusterStatus) DeepCopyInto(out *MongoDBClusterStatus) {
 	*out = *in
+	if in.PITR != nil {
+		in, out := &in.PITR, &out.PITR
+		*out = new(MongoDBPITRStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LatestSnapshot != nil {
+		in, out := &in.LatestSnapshot, &out.LatestSnapshot
+		*out = new(MongoDBSnapshotStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PendingSnapshot != nil {
+		in, out := &in.PendingSnapshot, &out.PendingSnapshot
+		*out = new(MongoDBSnapshotStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Bootstrap != nil {
+		in, out := &in.Bootstrap, &out.Bootstrap
+		*out = new(MongoDBBootstrapStatus)
+		**out = **in
+	}
+	if in.DisasterRecovery != nil {
+		in, out := &in.DisasterRecovery, &out.DisasterRecovery
+		*out = new(MongoDBDisasterRecoveryStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Hibernation != nil {
+		in, out := &in.Hibernation, &out.Hibernation
+		*out = new(MongoDBHibernationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AutoResync != nil {
+		in, out := &in.AutoResync, &out.AutoResync
+		*out = new(MongoDBAutoResyncStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBClusterStatus.
@@ -257,31 +689,488 @@ func (in *MongoDBClusterStatus) DeepCopy() *MongoDBClusterStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MongoDBList) DeepCopyInto(out *MongoDBList) {
+func (in *MongoDBDisasterRecovery) DeepCopyInto(out *MongoDBDisasterRecovery) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
+	if in.ExternalMembers != nil {
+		in, out := &in.ExternalMembers, &out.ExternalMembers
+		*out = make([]MongoDBExternalMember, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Promotion != nil {
+		in, out := &in.Promotion, &out.Promotion
+		*out = new(MongoDBPromotion)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBDisasterRecovery.
+func (in *MongoDBDisasterRecovery) DeepCopy() *MongoDBDisasterRecovery {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBDisasterRecovery)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBDisasterRecoveryStatus) DeepCopyInto(out *MongoDBDisasterRecoveryStatus) {
+	*out = *in
+	if in.PrimaryLostSince != nil {
+		in, out := &in.PrimaryLostSince, &out.PrimaryLostSince
+		*out = (*in).DeepCopy()
+	}
+	if in.PromotedAt != nil {
+		in, out := &in.PromotedAt, &out.PromotedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.RemovedMembers != nil {
+		in, out := &in.RemovedMembers, &out.RemovedMembers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBDisasterRecoveryStatus.
+func (in *MongoDBDisasterRecoveryStatus) DeepCopy() *MongoDBDisasterRecoveryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBDisasterRecoveryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBExternalMember) DeepCopyInto(out *MongoDBExternalMember) {
+	*out = *in
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBExternalMember.
+func (in *MongoDBExternalMember) DeepCopy() *MongoDBExternalMember {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBExternalMember)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBHibernationSchedule) DeepCopyInto(out *MongoDBHibernationSchedule) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBHibernationSchedule.
+func (in *MongoDBHibernationSchedule) DeepCopy() *MongoDBHibernationSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBHibernationSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBHibernationStatus) DeepCopyInto(out *MongoDBHibernationStatus) {
+	*out = *in
+	if in.LastTransitionTime != nil {
+		in, out := &in.LastTransitionTime, &out.LastTransitionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBHibernationStatus.
+func (in *MongoDBHibernationStatus) DeepCopy() *MongoDBHibernationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBHibernationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBList) DeepCopyInto(out *MongoDBList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MongoDB, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBList.
+func (in *MongoDBList) DeepCopy() *MongoDBList {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MongoDBList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBMonitoring) DeepCopyInto(out *MongoDBMonitoring) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LivenessProbe != nil {
+		in, out := &in.LivenessProbe, &out.LivenessProbe
+		*out = new(v1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReadinessProbe != nil {
+		in, out := &in.ReadinessProbe, &out.ReadinessProbe
+		*out = new(v1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBMonitoring.
+func (in *MongoDBMonitoring) DeepCopy() *MongoDBMonitoring {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBMonitoring)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBOpsParameters) DeepCopyInto(out *MongoDBOpsParameters) {
+	*out = *in
+	if in.Member != nil {
+		in, out := &in.Member, &out.Member
+		*out = new(int32)
+		**out = **in
+	}
+	if in.StepDownSeconds != nil {
+		in, out := &in.StepDownSeconds, &out.StepDownSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Collections != nil {
+		in, out := &in.Collections, &out.Collections
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBOpsParameters.
+func (in *MongoDBOpsParameters) DeepCopy() *MongoDBOpsParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBOpsParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBOpsRequest) DeepCopyInto(out *MongoDBOpsRequest) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBOpsRequest.
+func (in *MongoDBOpsRequest) DeepCopy() *MongoDBOpsRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBOpsRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MongoDBOpsRequest) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBOpsRequestEvent) DeepCopyInto(out *MongoDBOpsRequestEvent) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBOpsRequestEvent.
+func (in *MongoDBOpsRequestEvent) DeepCopy() *MongoDBOpsRequestEvent {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBOpsRequestEvent)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBOpsRequestList) DeepCopyInto(out *MongoDBOpsRequestList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MongoDBOpsRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBOpsRequestList.
+func (in *MongoDBOpsRequestList) DeepCopy() *MongoDBOpsRequestList {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBOpsRequestList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MongoDBOpsRequestList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBOpsRequestSpec) DeepCopyInto(out *MongoDBOpsRequestSpec) {
+	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = new(MongoDBOpsParameters)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBOpsRequestSpec.
+func (in *MongoDBOpsRequestSpec) DeepCopy() *MongoDBOpsRequestSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBOpsRequestSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBOpsRequestStatus) DeepCopyInto(out *MongoDBOpsRequestStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]MongoDBOpsRequestEvent, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBOpsRequestStatus.
+func (in *MongoDBOpsRequestStatus) DeepCopy() *MongoDBOpsRequestStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBOpsRequestStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBPITR) DeepCopyInto(out *MongoDBPITR) {
+	*out = *in
+	if in.ChunkIntervalSeconds != nil {
+		in, out := &in.ChunkIntervalSeconds, &out.ChunkIntervalSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.BaseBackupIntervalHours != nil {
+		in, out := &in.BaseBackupIntervalHours, &out.BaseBackupIntervalHours
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBPITR.
+func (in *MongoDBPITR) DeepCopy() *MongoDBPITR {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBPITR)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBPITRStatus) DeepCopyInto(out *MongoDBPITRStatus) {
+	*out = *in
+	if in.RecoverableFrom != nil {
+		in, out := &in.RecoverableFrom, &out.RecoverableFrom
+		*out = (*in).DeepCopy()
+	}
+	if in.RecoverableTo != nil {
+		in, out := &in.RecoverableTo, &out.RecoverableTo
+		*out = (*in).DeepCopy()
+	}
+	if in.LatestBaseBackup != nil {
+		in, out := &in.LatestBaseBackup, &out.LatestBaseBackup
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBPITRStatus.
+func (in *MongoDBPITRStatus) DeepCopy() *MongoDBPITRStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBPITRStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBPodDisruptionBudget) DeepCopyInto(out *MongoDBPodDisruptionBudget) {
+	*out = *in
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBPodDisruptionBudget.
+func (in *MongoDBPodDisruptionBudget) DeepCopy() *MongoDBPodDisruptionBudget {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBPodDisruptionBudget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBPromotion) DeepCopyInto(out *MongoDBPromotion) {
+	*out = *in
+	if in.AutomaticAfterSeconds != nil {
+		in, out := &in.AutomaticAfterSeconds, &out.AutomaticAfterSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBPromotion.
+func (in *MongoDBPromotion) DeepCopy() *MongoDBPromotion {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBPromotion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBRestore) DeepCopyInto(out *MongoDBRestore) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBRestore.
+func (in *MongoDBRestore) DeepCopy() *MongoDBRestore {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBRestore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MongoDBRestore) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBRestoreList) DeepCopyInto(out *MongoDBRestoreList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]MongoDB, len(*in))
+		*out = make([]MongoDBRestore, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBList.
-func (in *MongoDBList) DeepCopy() *MongoDBList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBRestoreList.
+func (in *MongoDBRestoreList) DeepCopy() *MongoDBRestoreList {
 	if in == nil {
 		return nil
 	}
-	out := new(MongoDBList)
+	out := new(MongoDBRestoreList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *MongoDBList) DeepCopyObject() runtime.Object {
+func (in *MongoDBRestoreList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -289,62 +1178,151 @@ func (in *MongoDBList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MongoDBMonitoring) DeepCopyInto(out *MongoDBMonitoring) {
+func (in *MongoDBRestoreSpec) DeepCopyInto(out *MongoDBRestoreSpec) {
 	*out = *in
-	if in.Resources != nil {
-		in, out := &in.Resources, &out.Resources
-		*out = new(v1.ResourceRequirements)
+	if in.TargetTime != nil {
+		in, out := &in.TargetTime, &out.TargetTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBRestoreSpec.
+func (in *MongoDBRestoreSpec) DeepCopy() *MongoDBRestoreSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBRestoreSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBRestoreStatus) DeepCopyInto(out *MongoDBRestoreStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBRestoreStatus.
+func (in *MongoDBRestoreStatus) DeepCopy() *MongoDBRestoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBRestoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBSecurity) DeepCopyInto(out *MongoDBSecurity) {
+	*out = *in
+	in.SecretRef.DeepCopyInto(&out.SecretRef)
+	if in.PasswordGenerator != nil {
+		in, out := &in.PasswordGenerator, &out.PasswordGenerator
+		*out = new(PasswordGenerator)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Vault != nil {
+		in, out := &in.Vault, &out.Vault
+		*out = new(VaultCredentialSource)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBMonitoring.
-func (in *MongoDBMonitoring) DeepCopy() *MongoDBMonitoring {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBSecurity.
+func (in *MongoDBSecurity) DeepCopy() *MongoDBSecurity {
 	if in == nil {
 		return nil
 	}
-	out := new(MongoDBMonitoring)
+	out := new(MongoDBSecurity)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MongoDBPodDisruptionBudget) DeepCopyInto(out *MongoDBPodDisruptionBudget) {
+func (in *MongoDBService) DeepCopyInto(out *MongoDBService) {
 	*out = *in
-	if in.MinAvailable != nil {
-		in, out := &in.MinAvailable, &out.MinAvailable
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
 		*out = new(int32)
 		**out = **in
 	}
-	if in.MaxUnavailable != nil {
-		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+	if in.NodePort != nil {
+		in, out := &in.NodePort, &out.NodePort
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LoadBalancerSourceRanges != nil {
+		in, out := &in.LoadBalancerSourceRanges, &out.LoadBalancerSourceRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBService.
+func (in *MongoDBService) DeepCopy() *MongoDBService {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBService)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBSnapshotBackup) DeepCopyInto(out *MongoDBSnapshotBackup) {
+	*out = *in
+	if in.VolumeSnapshotClassName != nil {
+		in, out := &in.VolumeSnapshotClassName, &out.VolumeSnapshotClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.IntervalHours != nil {
+		in, out := &in.IntervalHours, &out.IntervalHours
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
 		*out = new(int32)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBPodDisruptionBudget.
-func (in *MongoDBPodDisruptionBudget) DeepCopy() *MongoDBPodDisruptionBudget {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBSnapshotBackup.
+func (in *MongoDBSnapshotBackup) DeepCopy() *MongoDBSnapshotBackup {
 	if in == nil {
 		return nil
 	}
-	out := new(MongoDBPodDisruptionBudget)
+	out := new(MongoDBSnapshotBackup)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MongoDBSecurity) DeepCopyInto(out *MongoDBSecurity) {
+func (in *MongoDBSnapshotStatus) DeepCopyInto(out *MongoDBSnapshotStatus) {
 	*out = *in
-	in.SecretRef.DeepCopyInto(&out.SecretRef)
+	if in.OptimeDate != nil {
+		in, out := &in.OptimeDate, &out.OptimeDate
+		*out = (*in).DeepCopy()
+	}
+	if in.CreationTime != nil {
+		in, out := &in.CreationTime, &out.CreationTime
+		*out = (*in).DeepCopy()
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBSecurity.
-func (in *MongoDBSecurity) DeepCopy() *MongoDBSecurity {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBSnapshotStatus.
+func (in *MongoDBSnapshotStatus) DeepCopy() *MongoDBSnapshotStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(MongoDBSecurity)
+	out := new(MongoDBSnapshotStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -373,6 +1351,40 @@ func (in *MongoDBSpec) DeepCopyInto(out *MongoDBSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.MongodConfig != nil {
+		in, out := &in.MongodConfig, &out.MongodConfig
+		*out = new(MongodConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CommonLabels != nil {
+		in, out := &in.CommonLabels, &out.CommonLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.CommonAnnotations != nil {
+		in, out := &in.CommonAnnotations, &out.CommonAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MetadataOverrides != nil {
+		in, out := &in.MetadataOverrides, &out.MetadataOverrides
+		*out = new(MetadataOverrides)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(MongoDBService)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HibernationSchedule != nil {
+		in, out := &in.HibernationSchedule, &out.HibernationSchedule
+		*out = new(MongoDBHibernationSchedule)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBSpec.
@@ -388,6 +1400,11 @@ func (in *MongoDBSpec) DeepCopy() *MongoDBSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MongoDBStatus) DeepCopyInto(out *MongoDBStatus) {
 	*out = *in
+	if in.Hibernation != nil {
+		in, out := &in.Hibernation, &out.Hibernation
+		*out = new(MongoDBHibernationStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBStatus.
@@ -400,6 +1417,192 @@ func (in *MongoDBStatus) DeepCopy() *MongoDBStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBTopologySpread) DeepCopyInto(out *MongoDBTopologySpread) {
+	*out = *in
+	if in.MaxSkew != nil {
+		in, out := &in.MaxSkew, &out.MaxSkew
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBTopologySpread.
+func (in *MongoDBTopologySpread) DeepCopy() *MongoDBTopologySpread {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBTopologySpread)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongodConfig) DeepCopyInto(out *MongodConfig) {
+	*out = *in
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		*out = new(MongodStorageConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Net != nil {
+		in, out := &in.Net, &out.Net
+		*out = new(MongodNetConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OperationProfiling != nil {
+		in, out := &in.OperationProfiling, &out.OperationProfiling
+		*out = new(MongodOperationProfilingConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SetParameter != nil {
+		in, out := &in.SetParameter, &out.SetParameter
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongodConfig.
+func (in *MongodConfig) DeepCopy() *MongodConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MongodConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongodNetConfig) DeepCopyInto(out *MongodNetConfig) {
+	*out = *in
+	if in.MaxIncomingConnections != nil {
+		in, out := &in.MaxIncomingConnections, &out.MaxIncomingConnections
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongodNetConfig.
+func (in *MongodNetConfig) DeepCopy() *MongodNetConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MongodNetConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongodOperationProfilingConfig) DeepCopyInto(out *MongodOperationProfilingConfig) {
+	*out = *in
+	if in.SlowOpThresholdMs != nil {
+		in, out := &in.SlowOpThresholdMs, &out.SlowOpThresholdMs
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongodOperationProfilingConfig.
+func (in *MongodOperationProfilingConfig) DeepCopy() *MongodOperationProfilingConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MongodOperationProfilingConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongodStorageConfig) DeepCopyInto(out *MongodStorageConfig) {
+	*out = *in
+	if in.DirectoryPerDB != nil {
+		in, out := &in.DirectoryPerDB, &out.DirectoryPerDB
+		*out = new(bool)
+		**out = **in
+	}
+	if in.WiredTiger != nil {
+		in, out := &in.WiredTiger, &out.WiredTiger
+		*out = new(MongodWiredTigerConfig)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongodStorageConfig.
+func (in *MongodStorageConfig) DeepCopy() *MongodStorageConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MongodStorageConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongodWiredTigerConfig) DeepCopyInto(out *MongodWiredTigerConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongodWiredTigerConfig.
+func (in *MongodWiredTigerConfig) DeepCopy() *MongodWiredTigerConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MongodWiredTigerConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectMetadata) DeepCopyInto(out *ObjectMetadata) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectMetadata.
+func (in *ObjectMetadata) DeepCopy() *ObjectMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PasswordGenerator) DeepCopyInto(out *PasswordGenerator) {
+	*out = *in
+	if in.Length != nil {
+		in, out := &in.Length, &out.Length
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PasswordGenerator.
+func (in *PasswordGenerator) DeepCopy() *PasswordGenerator {
+	if in == nil {
+		return nil
+	}
+	out := new(PasswordGenerator)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Storage) DeepCopyInto(out *Storage) {
 	*out = *in
@@ -424,3 +1627,18 @@ func (in *Storage) DeepCopy() *Storage {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultCredentialSource) DeepCopyInto(out *VaultCredentialSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultCredentialSource.
+func (in *VaultCredentialSource) DeepCopy() *VaultCredentialSource {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultCredentialSource)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,87 @@
+package backup
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// BaseBackupDirectory is the storage directory of full backups, named <end timestamp>.archive.gz
+	BaseBackupDirectory = "base"
+	// OplogDirectory is the storage directory of oplog chunks, named <from timestamp>-<to timestamp>.bson.gz
+	OplogDirectory = "oplog"
+)
+
+// Window is the recoverable time window of the archived backups
+type Window struct {
+	From             time.Time
+	To               time.Time
+	LatestBaseBackup time.Time
+}
+
+// oplogChunk is the time range of an archived oplog chunk
+type oplogChunk struct {
+	From int64
+	To   int64
+}
+
+// RecoverableWindow is a method to compute the recoverable time window from the archived object keys
+func RecoverableWindow(prefix string, keys []string) (*Window, bool) {
+	var bases []int64
+	var chunks []oplogChunk
+	for _, key := range keys {
+		name := strings.TrimPrefix(strings.TrimPrefix(key, prefix), "/")
+		switch {
+		case strings.HasPrefix(name, BaseBackupDirectory+"/"):
+			end, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(name, BaseBackupDirectory+"/"), ".archive.gz"), 10, 64)
+			if err == nil {
+				bases = append(bases, end)
+			}
+		case strings.HasPrefix(name, OplogDirectory+"/"):
+			bounds := strings.SplitN(strings.TrimSuffix(strings.TrimPrefix(name, OplogDirectory+"/"), ".bson.gz"), "-", 2)
+			if len(bounds) != 2 {
+				continue
+			}
+			from, fromErr := strconv.ParseInt(bounds[0], 10, 64)
+			to, toErr := strconv.ParseInt(bounds[1], 10, 64)
+			if fromErr == nil && toErr == nil {
+				chunks = append(chunks, oplogChunk{From: from, To: to})
+			}
+		}
+	}
+	if len(bases) == 0 {
+		return nil, false
+	}
+	sort.Slice(bases, func(i, j int) bool { return bases[i] < bases[j] })
+	latestBase := bases[len(bases)-1]
+	window := &Window{From: time.Unix(latestBase, 0), To: time.Unix(latestBase, 0), LatestBaseBackup: time.Unix(latestBase, 0)}
+	if len(chunks) == 0 {
+		return window, true
+	}
+
+	// the window ends with the latest chunk and starts on the first base backup of its contiguous chain
+	sort.Slice(chunks, func(i, j int) bool { return chunks[i].To > chunks[j].To })
+	previous := map[int64]int64{}
+	for _, chunk := range chunks {
+		previous[chunk.To] = chunk.From
+	}
+	end := chunks[0].To
+	start := chunks[0].From
+	for {
+		from, ok := previous[start]
+		if !ok || from >= start {
+			break
+		}
+		start = from
+	}
+	for _, base := range bases {
+		if base >= start && base <= end {
+			window.From = time.Unix(base, 0)
+			window.To = time.Unix(end, 0)
+			return window, true
+		}
+	}
+	return window, true
+}
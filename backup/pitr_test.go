@@ -0,0 +1,84 @@
+package backup
+
+import (
+	"testing"
+)
+
+func TestRecoverableWindow(t *testing.T) {
+	tests := []struct {
+		name     string
+		keys     []string
+		wantOk   bool
+		wantFrom int64
+		wantTo   int64
+		wantBase int64
+	}{
+		{name: "no backup"},
+		{name: "oplog without base backup", keys: []string{"default/mongodb/oplog/90-160.bson.gz"}},
+		{name: "base backup only", keys: []string{"default/mongodb/base/100.archive.gz"}, wantOk: true, wantFrom: 100, wantTo: 100, wantBase: 100},
+		{
+			name:     "contiguous chain",
+			keys:     []string{"default/mongodb/base/100.archive.gz", "default/mongodb/oplog/160-220.bson.gz", "default/mongodb/oplog/90-160.bson.gz"},
+			wantOk:   true,
+			wantFrom: 100,
+			wantTo:   220,
+			wantBase: 100,
+		},
+		{
+			name: "window starts on the first base backup of the chain",
+			keys: []string{
+				"default/mongodb/base/100.archive.gz", "default/mongodb/base/200.archive.gz",
+				"default/mongodb/oplog/90-160.bson.gz", "default/mongodb/oplog/160-220.bson.gz", "default/mongodb/oplog/220-280.bson.gz",
+			},
+			wantOk:   true,
+			wantFrom: 100,
+			wantTo:   280,
+			wantBase: 200,
+		},
+		{
+			name: "gap after a recreated archiver",
+			keys: []string{
+				"default/mongodb/base/100.archive.gz", "default/mongodb/base/310.archive.gz",
+				"default/mongodb/oplog/90-160.bson.gz", "default/mongodb/oplog/300-360.bson.gz", "default/mongodb/oplog/360-420.bson.gz",
+			},
+			wantOk:   true,
+			wantFrom: 310,
+			wantTo:   420,
+			wantBase: 310,
+		},
+		{
+			name:     "latest chain without base backup",
+			keys:     []string{"default/mongodb/base/100.archive.gz", "default/mongodb/oplog/90-160.bson.gz", "default/mongodb/oplog/220-280.bson.gz"},
+			wantOk:   true,
+			wantFrom: 100,
+			wantTo:   100,
+			wantBase: 100,
+		},
+		{
+			name:     "unknown keys are ignored",
+			keys:     []string{"default/mongodb/base/100.archive.gz", "default/mongodb/base/latest.archive.gz", "default/mongodb/oplog/160.bson.gz", "default/mongodb/other/1-2"},
+			wantOk:   true,
+			wantFrom: 100,
+			wantTo:   100,
+			wantBase: 100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window, ok := RecoverableWindow("default/mongodb", tt.keys)
+			if ok != tt.wantOk {
+				t.Fatalf("got ok %v, want %v", ok, tt.wantOk)
+			}
+			if !ok {
+				if window != nil {
+					t.Errorf("got window %+v, want none", window)
+				}
+				return
+			}
+			if window.From.Unix() != tt.wantFrom || window.To.Unix() != tt.wantTo || window.LatestBaseBackup.Unix() != tt.wantBase {
+				t.Errorf("got window from %d to %d with base %d, want from %d to %d with base %d",
+					window.From.Unix(), window.To.Unix(), window.LatestBaseBackup.Unix(), tt.wantFrom, tt.wantTo, tt.wantBase)
+			}
+		})
+	}
+}
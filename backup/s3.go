@@ -0,0 +1,123 @@
+package backup
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultRegion    = "us-east-1"
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// Storage is the S3 compatible object storage where MongoDB backups are shipped
+type Storage struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+}
+
+// listBucketResult is the response of ListObjectsV2 API
+type listBucketResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// ListObjects is a method to list the object keys of storage under a prefix
+func (s Storage) ListObjects(prefix string) ([]string, error) {
+	var keys []string
+	token := ""
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", prefix)
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		var result listBucketResult
+		if err := s.get("/"+s.Bucket+"/", query, &result); err != nil {
+			return nil, err
+		}
+		for _, object := range result.Contents {
+			keys = append(keys, object.Key)
+		}
+		if !result.IsTruncated {
+			return keys, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// get is a method to send a signed GET request on storage and decode the XML response
+func (s Storage) get(path string, query url.Values, output interface{}) error {
+	endpoint, err := url.Parse(s.Endpoint)
+	if err != nil {
+		return err
+	}
+	canonicalQuery := strings.ReplaceAll(query.Encode(), "+", "%20")
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s://%s%s?%s", endpoint.Scheme, endpoint.Host, path, canonicalQuery), nil)
+	if err != nil {
+		return err
+	}
+	s.sign(request, endpoint.Host, path, canonicalQuery, time.Now().UTC())
+	client := &http.Client{Timeout: 30 * time.Second}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("object storage request failed with status %s", response.Status)
+	}
+	return xml.NewDecoder(response.Body).Decode(output)
+}
+
+// sign is a method to add the AWS signature version 4 on a request
+func (s Storage) sign(request *http.Request, host, path, canonicalQuery string, now time.Time) {
+	region := s.Region
+	if region == "" {
+		region = defaultRegion
+	}
+	amzDate := now.Format("20060102T150405Z")
+	dateStamp := now.Format("20060102")
+	request.Header.Set("x-amz-date", amzDate)
+	request.Header.Set("x-amz-content-sha256", emptyPayloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := fmt.Sprintf("host:%s\nx-amz-content-sha256:%s\nx-amz-date:%s\n", host, emptyPayloadHash, amzDate)
+	canonicalRequest := strings.Join([]string{http.MethodGet, path, canonicalQuery, canonicalHeaders, signedHeaders, emptyPayloadHash}, "\n")
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", dateStamp, region)
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, hashHex(canonicalRequest)}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretKey), dateStamp)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	request.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", s.AccessKey, scope, signedHeaders, signature))
+}
+
+// hashHex returns the hex encoded sha256 of data
+func hashHex(data string) string {
+	hash := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(hash[:])
+}
+
+// hmacSHA256 returns the HMAC of data with key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -19,6 +19,7 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -29,6 +30,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	"mongodb-operator/k8sgo"
+	"mongodb-operator/metrics"
 
 	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
 )
@@ -36,8 +38,9 @@ import (
 // MongoDBReconciler reconciles a MongoDB object
 type MongoDBReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	Log         logr.Logger
+	Scheme      *runtime.Scheme
+	PruneDryRun bool
 }
 
 //+kubebuilder:rbac:groups=opstreelabs.in,resources=mongodbs,verbs=get;list;watch;create;update;patch;delete
@@ -48,19 +51,51 @@ type MongoDBReconciler struct {
 //+kubebuilder:rbac:groups="",resources=configmaps;events;services;secrets,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
-func (r *MongoDBReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+func (r *MongoDBReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
 	instance := &opstreelabsinv1alpha1.MongoDB{}
-	err := r.Client.Get(context.TODO(), req.NamespacedName, instance)
+	err = r.Client.Get(context.TODO(), req.NamespacedName, instance)
 	if err != nil {
 		if errors.IsNotFound(err) {
+			// a standalone reports no replica set series, they belong to the MongoDBCluster with the same name
+			metrics.DeleteReconcileSeries("MongoDB", req.Namespace, req.Name)
 			return ctrl.Result{RequeueAfter: time.Second * 10}, nil
 		}
+		metrics.ObserveReconcile("MongoDB", req.Namespace, req.Name, err)
 		return ctrl.Result{RequeueAfter: time.Second * 10}, err
 	}
+	defer func() {
+		metrics.ObserveReconcile("MongoDB", req.Namespace, req.Name, err)
+	}()
 	if err := controllerutil.SetControllerReference(instance, instance, r.Scheme); err != nil {
 		return ctrl.Result{RequeueAfter: time.Second * 10}, err
 	}
-	if !k8sgo.CheckSecretExist(instance.Namespace, fmt.Sprintf("%s-%s", instance.ObjectMeta.Name, "standalone-monitoring")) {
+	if instance.Spec.Paused {
+		// a paused MongoDB is reconciled again when its spec changes
+		return ctrl.Result{}, nil
+	}
+	adminSecret, err := k8sgo.CreateMongoStandaloneAdminSecret(instance)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	if instance.Status.AdminSecret != adminSecret {
+		instance.Status.AdminSecret = adminSecret
+		err = r.Client.Status().Update(context.TODO(), instance)
+		if err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+	}
+	hibernation, err := k8sgo.HibernateMongoStandalone(instance)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	if !reflect.DeepEqual(instance.Status.Hibernation, hibernation) {
+		instance.Status.Hibernation = hibernation
+		err = r.Client.Status().Update(context.TODO(), instance)
+		if err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+	}
+	if instance.Spec.MongoDBMonitoring != nil && !k8sgo.CheckSecretExist(instance.Namespace, fmt.Sprintf("%s-%s", instance.ObjectMeta.Name, "standalone-monitoring")) {
 		err = k8sgo.CreateMongoMonitoringSecret(instance)
 		if err != nil {
 			return ctrl.Result{RequeueAfter: time.Second * 10}, err
@@ -74,6 +109,13 @@ func (r *MongoDBReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	if err != nil {
 		return ctrl.Result{RequeueAfter: time.Second * 10}, err
 	}
+	err = k8sgo.PruneMongoStandaloneObjects(instance, r.PruneDryRun)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	if k8sgo.IsHibernated(instance.Status.Hibernation) {
+		return ctrl.Result{RequeueAfter: time.Second * 60}, nil
+	}
 	mongoDBSTS, err := k8sgo.GetStateFulSet(instance.Namespace, fmt.Sprintf("%s-%s", instance.ObjectMeta.Name, "standalone"))
 	if err != nil {
 		return ctrl.Result{RequeueAfter: time.Second * 10}, err
@@ -81,8 +123,22 @@ func (r *MongoDBReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	if int(mongoDBSTS.Status.ReadyReplicas) != int(1) {
 		return ctrl.Result{RequeueAfter: time.Second * 60}, nil
 	} else {
-		if !k8sgo.CheckMonitoringUser(instance) {
-			err = k8sgo.CreateMongoDBMonitoringUser(instance)
+		if instance.Status.Hibernation != nil {
+			instance.Status.Hibernation = nil
+			err = r.Client.Status().Update(context.TODO(), instance)
+			if err != nil {
+				return ctrl.Result{RequeueAfter: time.Second * 10}, err
+			}
+		}
+		if instance.Spec.MongoDBMonitoring != nil {
+			if !k8sgo.CheckMonitoringUser(instance) {
+				err = k8sgo.CreateMongoDBMonitoringUser(instance)
+				if err != nil {
+					return ctrl.Result{RequeueAfter: time.Second * 10}, err
+				}
+			}
+		} else if !r.PruneDryRun && k8sgo.CheckMonitoringUser(instance) {
+			err = k8sgo.DeleteMongoDBMonitoringUser(instance)
 			if err != nil {
 				return ctrl.Result{RequeueAfter: time.Second * 10}, err
 			}
@@ -19,59 +19,146 @@ package controllers
 import (
 	"context"
 	"fmt"
+	batchv1 "k8s.io/api/batch/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"reflect"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 	"time"
 
 	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
 	"mongodb-operator/k8sgo"
+	"mongodb-operator/metrics"
 )
 
+// replicaSetResyncPeriod is the period to observe the replica set again, elections and member states are not Kubernetes events
+const replicaSetResyncPeriod = time.Second * 30
+
 // MongoDBClusterReconciler reconciles a MongoDBCluster object
 type MongoDBClusterReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme      *runtime.Scheme
+	Recorder    record.EventRecorder
+	PruneDryRun bool
 }
 
 //+kubebuilder:rbac:groups=opstreelabs.in,resources=mongodbclusters,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=opstreelabs.in,resources=mongodbclusters/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=opstreelabs.in,resources=mongodbclusters/finalizers,verbs=update
 //+kubebuilder:rbac:groups="policy",resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;patch
+//+kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+//+kubebuilder:rbac:groups="apps",resources=deployments,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="snapshot.storage.k8s.io",resources=volumesnapshots,verbs=get;list;watch;create;delete
+//+kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;create;delete
+//+kubebuilder:rbac:groups="",resources=persistentvolumes,verbs=get;list;watch;patch
+//+kubebuilder:rbac:groups="batch",resources=jobs,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
-func (r *MongoDBClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+func (r *MongoDBClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
 	instance := &opstreelabsinv1alpha1.MongoDBCluster{}
-	err := r.Client.Get(context.TODO(), req.NamespacedName, instance)
+	err = r.Client.Get(context.TODO(), req.NamespacedName, instance)
 	if err != nil {
 		if errors.IsNotFound(err) {
+			metrics.DeleteReconcileSeries("MongoDBCluster", req.Namespace, req.Name)
+			metrics.DeleteReplicaSetSeries(req.Namespace, req.Name)
 			return ctrl.Result{RequeueAfter: time.Second * 10}, nil
 		}
+		metrics.ObserveReconcile("MongoDBCluster", req.Namespace, req.Name, err)
 		return ctrl.Result{RequeueAfter: time.Second * 10}, err
 	}
+	defer func() {
+		metrics.ObserveReconcile("MongoDBCluster", req.Namespace, req.Name, err)
+	}()
 	if err := controllerutil.SetControllerReference(instance, instance, r.Scheme); err != nil {
 		return ctrl.Result{RequeueAfter: time.Second * 10}, err
 	}
-	if !k8sgo.CheckSecretExist(instance.Namespace, fmt.Sprintf("%s-%s", instance.ObjectMeta.Name, "cluster-monitoring")) {
+	if instance.Spec.Paused {
+		// a paused cluster is reconciled again when its spec changes
+		return ctrl.Result{}, nil
+	}
+	adminSecret, err := k8sgo.CreateMongoClusterAdminSecret(instance)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	if instance.Status.AdminSecret != adminSecret {
+		instance.Status.AdminSecret = adminSecret
+		err = r.Client.Status().Update(context.TODO(), instance)
+		if err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+	}
+	if instance.Spec.MongoDBMonitoring != nil && !k8sgo.CheckSecretExist(instance.Namespace, fmt.Sprintf("%s-%s", instance.ObjectMeta.Name, "cluster-monitoring")) {
 		err = k8sgo.CreateMongoClusterMonitoringSecret(instance)
 		if err != nil {
 			return ctrl.Result{RequeueAfter: time.Second * 10}, err
 		}
 	}
-	err = k8sgo.CreateMongoClusterSetup(instance)
+	adopted, err := k8sgo.AdoptMongoDBStandalone(instance)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	if !adopted {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, nil
+	}
+	hibernation, err := k8sgo.HibernateMongoCluster(instance)
 	if err != nil {
 		return ctrl.Result{RequeueAfter: time.Second * 10}, err
 	}
-	err = k8sgo.CreateMongoClusterMonitoringService(instance)
+	if !reflect.DeepEqual(instance.Status.Hibernation, hibernation) {
+		instance.Status.Hibernation = hibernation
+		err = r.Client.Status().Update(context.TODO(), instance)
+		if err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+	}
+	err = k8sgo.CreateMongoClusterSetup(instance)
 	if err != nil {
 		return ctrl.Result{RequeueAfter: time.Second * 10}, err
 	}
+	if instance.Spec.MongoDBMonitoring != nil {
+		err = k8sgo.CreateMongoClusterMonitoringService(instance)
+		if err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+	}
 	err = k8sgo.CreateMongoClusterService(instance)
 	if err != nil {
 		return ctrl.Result{RequeueAfter: time.Second * 10}, err
 	}
+	err = k8sgo.CreateMongoClusterClientService(instance)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	err = k8sgo.PruneMongoClusterObjects(instance, r.PruneDryRun)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	if k8sgo.IsHibernated(instance.Status.Hibernation) {
+		return ctrl.Result{RequeueAfter: time.Second * 60}, nil
+	}
+	// stale members are never ready, so they are resynced before waiting for the statefulset
+	autoResync, resyncErr := k8sgo.AutoResyncMongoCluster(instance, r.Recorder)
+	if !reflect.DeepEqual(instance.Status.AutoResync, autoResync) {
+		instance.Status.AutoResync = autoResync
+		err = r.Client.Status().Update(context.TODO(), instance)
+		if err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+	}
+	if resyncErr != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, resyncErr
+	}
+	if autoResync != nil && autoResync.Phase == k8sgo.AutoResyncPhaseRunning {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, nil
+	}
 	mongoDBSTS, err := k8sgo.GetStateFulSet(instance.Namespace, fmt.Sprintf("%s-%s", instance.ObjectMeta.Name, "cluster"))
 	if err != nil {
 		return ctrl.Result{RequeueAfter: time.Second * 10}, err
@@ -79,6 +166,21 @@ func (r *MongoDBClusterReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	if int(mongoDBSTS.Status.ReadyReplicas) != int(*instance.Spec.MongoDBClusterSize) {
 		return ctrl.Result{RequeueAfter: time.Second * 60}, nil
 	}
+	if k8sgo.IsMongoClusterStandby(instance) {
+		// the replica set of a standby cluster is managed by the primary site until the promotion
+		disasterRecovery, err := k8sgo.ReconcileMongoClusterStandby(instance)
+		if err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+		if !reflect.DeepEqual(instance.Status.DisasterRecovery, disasterRecovery) {
+			instance.Status.DisasterRecovery = disasterRecovery
+			err = r.Client.Status().Update(context.TODO(), instance)
+			if err != nil {
+				return ctrl.Result{RequeueAfter: time.Second * 10}, err
+			}
+		}
+		return ctrl.Result{RequeueAfter: time.Second * 10}, nil
+	}
 	state, err := k8sgo.CheckMongoClusterStateInitialized(instance)
 	if err != nil || !state {
 		err = k8sgo.InitializeMongoDBCluster(instance)
@@ -86,18 +188,123 @@ func (r *MongoDBClusterReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 			return ctrl.Result{RequeueAfter: time.Second * 10}, err
 		}
 	}
-	if !k8sgo.CheckMongoDBClusterMonitoringUser(instance) {
-		err = k8sgo.CreateMongoDBClusterMonitoringUser(instance)
+	bootstrap, err := k8sgo.BootstrapMongoDBCluster(instance)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	if !reflect.DeepEqual(instance.Status.Bootstrap, bootstrap) {
+		instance.Status.Bootstrap = bootstrap
+		err = r.Client.Status().Update(context.TODO(), instance)
+		if err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+	}
+	if bootstrap != nil && bootstrap.Phase != k8sgo.BootstrapPhaseCompleted {
+		return ctrl.Result{RequeueAfter: time.Second * 30}, nil
+	}
+	if instance.Spec.MongoDBMonitoring != nil {
+		if !k8sgo.CheckMongoDBClusterMonitoringUser(instance) {
+			err = k8sgo.CreateMongoDBClusterMonitoringUser(instance)
+			if err != nil {
+				return ctrl.Result{RequeueAfter: time.Second * 10}, err
+			}
+		}
+	} else if !r.PruneDryRun && k8sgo.CheckMongoDBClusterMonitoringUser(instance) {
+		// the monitoring secret is pruned, the user is recreated with a new password when monitoring is enabled again
+		err = k8sgo.DeleteMongoDBClusterMonitoringUser(instance)
+		if err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+	}
+	if instance.Spec.Backup != nil {
+		if !k8sgo.CheckSecretExist(instance.Namespace, fmt.Sprintf("%s-%s", instance.ObjectMeta.Name, "cluster-backup")) {
+			err = k8sgo.CreateMongoClusterBackupSecret(instance)
+			if err != nil {
+				return ctrl.Result{RequeueAfter: time.Second * 10}, err
+			}
+		}
+		if !k8sgo.CheckMongoDBClusterBackupUser(instance) {
+			err = k8sgo.CreateMongoDBClusterBackupUser(instance)
+			if err != nil {
+				return ctrl.Result{RequeueAfter: time.Second * 10}, err
+			}
+		}
+	}
+	restoring, err := r.restoreInProgress(instance)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	err = k8sgo.CreateOrDeleteMongoClusterPITR(instance, restoring)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	err = k8sgo.ReconfigureMongoDBCluster(instance)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	status, err := k8sgo.GetMongoClusterStatus(instance)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	k8sgo.RecordMongoClusterTopology(instance, status)
+	err = k8sgo.UpdateMongoClusterRoleLabels(instance, status)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	clusterStatus := instance.Status.DeepCopy()
+	if k8sgo.MongoClusterAwake(status) {
+		clusterStatus.Hibernation = nil
+	}
+	pendingSnapshot, snapshot, err := k8sgo.CreateMongoClusterSnapshot(instance, status)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	clusterStatus.PendingSnapshot = pendingSnapshot
+	if snapshot != nil {
+		clusterStatus.LatestSnapshot = snapshot
+	}
+	clusterStatus.PITR, err = k8sgo.GetMongoClusterRecoverableWindow(instance)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	if !reflect.DeepEqual(instance.Status, *clusterStatus) {
+		instance.Status = *clusterStatus
+		err = r.Client.Status().Update(context.TODO(), instance)
 		if err != nil {
 			return ctrl.Result{RequeueAfter: time.Second * 10}, err
 		}
 	}
-	return ctrl.Result{}, nil
+	if clusterStatus.PendingSnapshot != nil {
+		// the snapshot member is locked until the VolumeSnapshot is taken
+		return ctrl.Result{RequeueAfter: time.Second * 2}, nil
+	}
+	return ctrl.Result{RequeueAfter: replicaSetResyncPeriod}, nil
+}
+
+// restoreInProgress is a method to check if a MongoDBRestore of the cluster is not finished
+func (r *MongoDBClusterReconciler) restoreInProgress(instance *opstreelabsinv1alpha1.MongoDBCluster) (bool, error) {
+	restores := &opstreelabsinv1alpha1.MongoDBRestoreList{}
+	err := r.Client.List(context.TODO(), restores, client.InNamespace(instance.Namespace))
+	if err != nil {
+		return false, err
+	}
+	for _, restore := range restores.Items {
+		if restore.Spec.ClusterName == instance.ObjectMeta.Name && restore.Status.Phase != restorePhaseSucceeded && restore.Status.Phase != restorePhaseFailed {
+			return true, nil
+		}
+	}
+	return false, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *MongoDBClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&opstreelabsinv1alpha1.MongoDBCluster{}).
+		Owns(&batchv1.Job{}).
+		// the oplog archiver is stopped as soon as a restore of the cluster is requested
+		Watches(&source.Kind{Type: &opstreelabsinv1alpha1.MongoDBRestore{}}, handler.EnqueueRequestsFromMapFunc(func(object client.Object) []reconcile.Request {
+			restore := object.(*opstreelabsinv1alpha1.MongoDBRestore)
+			return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: restore.Namespace, Name: restore.Spec.ClusterName}}}
+		})).
 		Complete(r)
 }
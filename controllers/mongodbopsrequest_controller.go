@@ -0,0 +1,156 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"time"
+
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"mongodb-operator/k8sgo"
+	"mongodb-operator/metrics"
+)
+
+const (
+	opsPhasePending   = "Pending"
+	opsPhaseRunning   = "Running"
+	opsPhaseSucceeded = "Succeeded"
+	opsPhaseFailed    = "Failed"
+)
+
+// MongoDBOpsRequestReconciler reconciles a MongoDBOpsRequest object
+type MongoDBOpsRequestReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=opstreelabs.in,resources=mongodbopsrequests,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=opstreelabs.in,resources=mongodbopsrequests/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=opstreelabs.in,resources=mongodbopsrequests/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;delete
+//+kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;delete
+
+// Reconcile executes the day-2 operations of a MongoDB cluster one at a time, and records their history
+func (r *MongoDBOpsRequestReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	instance := &opstreelabsinv1alpha1.MongoDBOpsRequest{}
+	err = r.Client.Get(context.TODO(), req.NamespacedName, instance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			metrics.DeleteReconcileSeries("MongoDBOpsRequest", req.Namespace, req.Name)
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	defer func() {
+		metrics.ObserveReconcile("MongoDBOpsRequest", req.Namespace, req.Name, err)
+	}()
+	if instance.Status.Phase == opsPhaseSucceeded || instance.Status.Phase == opsPhaseFailed {
+		return ctrl.Result{}, nil
+	}
+	cluster := &opstreelabsinv1alpha1.MongoDBCluster{}
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Namespace: instance.Namespace, Name: instance.Spec.ClusterName}, cluster)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	if instance.Status.Phase != opsPhaseRunning {
+		blocking, err := r.getBlockingRequest(instance)
+		if err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+		if blocking != "" {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, r.updateStatus(instance, opsPhasePending, fmt.Sprintf("Waiting for MongoDBOpsRequest %s to complete", blocking))
+		}
+		instance.Status.StartTime = &metav1.Time{Time: time.Now()}
+		err = r.updateStatus(instance, opsPhaseRunning, fmt.Sprintf("Running %s operation", instance.Spec.Type))
+		if err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+	}
+	done, message, err := k8sgo.RunMongoClusterOpsRequest(instance, cluster)
+	if err != nil {
+		if k8sgo.IsRetryableError(err) {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+		return ctrl.Result{}, r.updateStatus(instance, opsPhaseFailed, err.Error())
+	}
+	if !done {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, r.updateStatus(instance, opsPhaseRunning, message)
+	}
+	return ctrl.Result{}, r.updateStatus(instance, opsPhaseSucceeded, message)
+}
+
+// getBlockingRequest is a method to get the oldest unfinished request of the same cluster created before this one, operations run one at a time per cluster
+func (r *MongoDBOpsRequestReconciler) getBlockingRequest(instance *opstreelabsinv1alpha1.MongoDBOpsRequest) (string, error) {
+	requests := &opstreelabsinv1alpha1.MongoDBOpsRequestList{}
+	err := r.Client.List(context.TODO(), requests, client.InNamespace(instance.Namespace))
+	if err != nil {
+		return "", err
+	}
+	blocking := ""
+	for _, request := range requests.Items {
+		if request.Name == instance.Name || request.Spec.ClusterName != instance.Spec.ClusterName {
+			continue
+		}
+		if request.Status.Phase == opsPhaseSucceeded || request.Status.Phase == opsPhaseFailed {
+			continue
+		}
+		if request.Status.Phase == opsPhaseRunning {
+			return request.Name, nil
+		}
+		if requestedBefore(request, *instance) && blocking == "" {
+			blocking = request.Name
+		}
+	}
+	return blocking, nil
+}
+
+// requestedBefore is a method to order the requests by creation, the name breaks the ties
+func requestedBefore(request, other opstreelabsinv1alpha1.MongoDBOpsRequest) bool {
+	if !request.CreationTimestamp.Equal(&other.CreationTimestamp) {
+		return request.CreationTimestamp.Before(&other.CreationTimestamp)
+	}
+	return request.Name < other.Name
+}
+
+// updateStatus is a method to update the ops request status when it changed, each change is recorded in the history
+func (r *MongoDBOpsRequestReconciler) updateStatus(instance *opstreelabsinv1alpha1.MongoDBOpsRequest, phase, message string) error {
+	if instance.Status.Phase == phase && instance.Status.Message == message {
+		return nil
+	}
+	now := metav1.Time{Time: time.Now()}
+	instance.Status.Phase = phase
+	instance.Status.Message = message
+	if phase == opsPhaseSucceeded || phase == opsPhaseFailed {
+		instance.Status.CompletionTime = &now
+	}
+	instance.Status.History = append(instance.Status.History, opstreelabsinv1alpha1.MongoDBOpsRequestEvent{Time: now, Phase: phase, Message: message})
+	return r.Client.Status().Update(context.TODO(), instance)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *MongoDBOpsRequestReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&opstreelabsinv1alpha1.MongoDBOpsRequest{}).
+		Complete(r)
+}
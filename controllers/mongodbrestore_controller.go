@@ -0,0 +1,119 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"mongodb-operator/k8sgo"
+	"mongodb-operator/metrics"
+)
+
+const (
+	restorePhasePending   = "Pending"
+	restorePhaseRunning   = "Running"
+	restorePhaseSucceeded = "Succeeded"
+	restorePhaseFailed    = "Failed"
+)
+
+// MongoDBRestoreReconciler reconciles a MongoDBRestore object
+type MongoDBRestoreReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=opstreelabs.in,resources=mongodbrestores,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=opstreelabs.in,resources=mongodbrestores/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=opstreelabs.in,resources=mongodbrestores/finalizers,verbs=update
+//+kubebuilder:rbac:groups="batch",resources=jobs,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile restores a MongoDB cluster from its archived backups with a job, and tracks the job state
+func (r *MongoDBRestoreReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	instance := &opstreelabsinv1alpha1.MongoDBRestore{}
+	err = r.Client.Get(context.TODO(), req.NamespacedName, instance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			metrics.DeleteReconcileSeries("MongoDBRestore", req.Namespace, req.Name)
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	defer func() {
+		metrics.ObserveReconcile("MongoDBRestore", req.Namespace, req.Name, err)
+	}()
+	if instance.Status.Phase == restorePhaseSucceeded || instance.Status.Phase == restorePhaseFailed {
+		return ctrl.Result{}, nil
+	}
+	cluster := &opstreelabsinv1alpha1.MongoDBCluster{}
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Namespace: instance.Namespace, Name: instance.Spec.ClusterName}, cluster)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	if instance.Status.JobName == "" {
+		// the cluster controller stops the oplog archiver while the restore is not finished
+		stopped, err := k8sgo.CheckMongoClusterPITRStopped(cluster)
+		if err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+		if !stopped {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, r.updateStatus(instance, restorePhasePending, "Waiting for the oplog archiver to stop", "")
+		}
+	}
+	// the job is created again on the next reconciliation, only a failed job fails the restore
+	job, err := k8sgo.CreateMongoClusterRestoreJob(instance, cluster)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	phase, message := restorePhaseRunning, "Restoring the base backup and replaying the oplog"
+	if finished, succeeded := k8sgo.JobFinished(job); finished {
+		phase, message = restorePhaseFailed, "Restore job failed, check the logs of the job pods"
+		if succeeded {
+			phase, message = restorePhaseSucceeded, "MongoDB cluster is restored"
+		}
+	}
+	err = r.updateStatus(instance, phase, message, job.Name)
+	if err != nil || phase == restorePhaseRunning {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// updateStatus is a method to update the restore status when it changed
+func (r *MongoDBRestoreReconciler) updateStatus(instance *opstreelabsinv1alpha1.MongoDBRestore, phase, message, jobName string) error {
+	status := opstreelabsinv1alpha1.MongoDBRestoreStatus{Phase: phase, Message: message, JobName: jobName}
+	if instance.Status == status {
+		return nil
+	}
+	instance.Status = status
+	return r.Client.Status().Update(context.TODO(), instance)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *MongoDBRestoreReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&opstreelabsinv1alpha1.MongoDBRestore{}).
+		Owns(&batchv1.Job{}).
+		Complete(r)
+}
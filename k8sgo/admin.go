@@ -0,0 +1,116 @@
+package k8sgo
+
+import (
+	"fmt"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+)
+
+const (
+	// defaultAdminSecretKey is the key of the admin password in a generated secret
+	defaultAdminSecretKey = "password"
+	// defaultAdminPasswordLength is the length of a generated admin password
+	defaultAdminPasswordLength = 32
+)
+
+// CreateMongoClusterAdminSecret is a method to generate the admin password secret of MongoDB cluster when it does not exist, it returns the secret name,
+// which is empty when the admin password is kept in Vault
+func CreateMongoClusterAdminSecret(cr *opstreelabsinv1alpha1.MongoDBCluster) (string, error) {
+	if adminPasswordInVault(cr.Spec.MongoDBSecurity) {
+		return "", nil
+	}
+	secretName, secretKey := getMongoClusterAdminSecret(cr)
+	if CheckSecretExist(cr.Namespace, secretName) {
+		return secretName, nil
+	}
+	labels := map[string]string{
+		"app":           secretName,
+		"mongodb_setup": "cluster",
+		"role":          "cluster",
+	}
+	params := secretsParameters{
+		SecretsMeta: generateObjectMetaInformation(secretName, cr.Namespace, labels, generateAnnotations(), getMongoDBClusterMetadata(cr, "")),
+		OwnerDef:    mongoClusterAsOwner(cr),
+		Namespace:   cr.Namespace,
+		Labels:      labels,
+		Annotations: generateAnnotations(),
+		Password:    generateAdminPassword(cr.Spec.MongoDBSecurity.PasswordGenerator),
+		Name:        secretName,
+		SecretKey:   secretKey,
+	}
+	standaloneSecret := fmt.Sprintf("%s-%s", getAdoptedStandalone(cr), "standalone-admin")
+	if getAdoptedStandalone(cr) != "" && CheckSecretExist(cr.Namespace, standaloneSecret) {
+		// the admin user of the standalone comes with the adopted data, so its generated password is kept
+		params.Password = getProviderPassword(cr.ObjectMeta.Name, cr.Namespace, &kubernetesSecretProvider{Namespace: cr.Namespace, SecretName: standaloneSecret, SecretKey: defaultAdminSecretKey})
+	}
+	err := CreateSecret(params)
+	if err != nil {
+		return "", err
+	}
+	return secretName, nil
+}
+
+// CreateMongoStandaloneAdminSecret is a method to generate the admin password secret of MongoDB standalone when it does not exist, it returns the secret name,
+// which is empty when the admin password is kept in Vault
+func CreateMongoStandaloneAdminSecret(cr *opstreelabsinv1alpha1.MongoDB) (string, error) {
+	if adminPasswordInVault(cr.Spec.MongoDBSecurity) {
+		return "", nil
+	}
+	secretName, secretKey := getMongoStandaloneAdminSecret(cr)
+	if CheckSecretExist(cr.Namespace, secretName) {
+		return secretName, nil
+	}
+	labels := map[string]string{
+		"app":           secretName,
+		"mongodb_setup": "standalone",
+		"role":          "standalone",
+	}
+	params := secretsParameters{
+		SecretsMeta: generateObjectMetaInformation(secretName, cr.Namespace, labels, generateAnnotations(), getMongoDBStandaloneMetadata(cr, "")),
+		OwnerDef:    mongoAsOwner(cr),
+		Namespace:   cr.Namespace,
+		Labels:      labels,
+		Annotations: generateAnnotations(),
+		Password:    generateAdminPassword(cr.Spec.MongoDBSecurity.PasswordGenerator),
+		Name:        secretName,
+		SecretKey:   secretKey,
+	}
+	err := CreateSecret(params)
+	if err != nil {
+		return "", err
+	}
+	return secretName, nil
+}
+
+// getMongoClusterAdminSecret is a method to get the name and key of the admin password secret of MongoDB cluster
+func getMongoClusterAdminSecret(cr *opstreelabsinv1alpha1.MongoDBCluster) (string, string) {
+	return getAdminSecretRef(cr.Spec.MongoDBSecurity, fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster-admin"))
+}
+
+// getMongoStandaloneAdminSecret is a method to get the name and key of the admin password secret of MongoDB standalone
+func getMongoStandaloneAdminSecret(cr *opstreelabsinv1alpha1.MongoDB) (string, string) {
+	return getAdminSecretRef(cr.Spec.MongoDBSecurity, fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "standalone-admin"))
+}
+
+// getAdminSecretRef is a method to get the name and key of the admin password secret, the generated name is used when none is set
+func getAdminSecretRef(security *opstreelabsinv1alpha1.MongoDBSecurity, generatedName string) (string, string) {
+	secretName, secretKey := generatedName, defaultAdminSecretKey
+	if security.SecretRef.Name != nil && *security.SecretRef.Name != "" {
+		secretName = *security.SecretRef.Name
+	}
+	if security.SecretRef.Key != nil && *security.SecretRef.Key != "" {
+		secretKey = *security.SecretRef.Key
+	}
+	return secretName, secretKey
+}
+
+// generateAdminPassword is a method to generate an admin password with the configured length and charset
+func generateAdminPassword(generator *opstreelabsinv1alpha1.PasswordGenerator) string {
+	if generator == nil {
+		return generatePassword(defaultAdminPasswordLength, "")
+	}
+	length := defaultAdminPasswordLength
+	if generator.Length != nil {
+		length = int(*generator.Length)
+	}
+	return generatePassword(length, generator.Charset)
+}
@@ -0,0 +1,38 @@
+package k8sgo
+
+import (
+	"strings"
+	"testing"
+
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+)
+
+func TestGenerateAdminPassword(t *testing.T) {
+	alphanumeric := "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+	length := int32(64)
+	tests := []struct {
+		name       string
+		generator  *opstreelabsinv1alpha1.PasswordGenerator
+		wantLength int
+		wantChars  string
+	}{
+		{name: "default generator", wantLength: defaultAdminPasswordLength, wantChars: alphanumeric},
+		{name: "empty generator", generator: &opstreelabsinv1alpha1.PasswordGenerator{}, wantLength: defaultAdminPasswordLength, wantChars: alphanumeric},
+		{name: "custom length", generator: &opstreelabsinv1alpha1.PasswordGenerator{Length: &length}, wantLength: 64, wantChars: alphanumeric},
+		{name: "custom charset", generator: &opstreelabsinv1alpha1.PasswordGenerator{Charset: "abc._~-"}, wantLength: defaultAdminPasswordLength, wantChars: "abc._~-"},
+		{name: "custom length and charset", generator: &opstreelabsinv1alpha1.PasswordGenerator{Length: &length, Charset: "0123456789"}, wantLength: 64, wantChars: "0123456789"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			password := generateAdminPassword(tt.generator)
+			if len(password) != tt.wantLength {
+				t.Errorf("got password length %d, want %d", len(password), tt.wantLength)
+			}
+			for _, char := range password {
+				if !strings.ContainsRune(tt.wantChars, char) {
+					t.Errorf("got character %q in password, want only characters of %q", char, tt.wantChars)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package k8sgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"mongodb-operator/metrics"
+	mongogo "mongodb-operator/mongo"
+)
+
+const (
+	// AdoptStandaloneAnnotation is the annotation of MongoDB cluster naming the MongoDB standalone to adopt as first member
+	AdoptStandaloneAnnotation = "mongodb.opstreelabs.in/adopt-standalone"
+	adoptedByAnnotation       = "mongodb.opstreelabs.in/adopted-by"
+	reclaimPolicyAnnotation   = "mongodb.opstreelabs.in/reclaim-policy"
+)
+
+var mongoDBStandaloneResource = opstreelabsinv1alpha1.GroupVersion.WithResource("mongodbs")
+
+// AdoptMongoDBStandalone is a method to move the volume of a MongoDB standalone to the first member of MongoDB cluster, it returns true once the volume is adopted
+func AdoptMongoDBStandalone(cr *opstreelabsinv1alpha1.MongoDBCluster) (bool, error) {
+	defer metrics.StepTimer("cluster_adopt_standalone")()
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Standalone Adoption")
+	standalone := getAdoptedStandalone(cr)
+	if standalone == "" {
+		return true, nil
+	}
+	if cr.Spec.Storage == nil {
+		return false, fmt.Errorf("persistence must be enabled to adopt MongoDB standalone %s", standalone)
+	}
+	appName := fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster")
+	claimName := fmt.Sprintf("%s-%s-0", appName, appName)
+	_, err := generateK8sClient().CoreV1().PersistentVolumeClaims(cr.Namespace).Get(context.TODO(), claimName, metav1.GetOptions{})
+	if err == nil {
+		return true, nil
+	}
+	if !errors.IsNotFound(err) {
+		return false, err
+	}
+	// the MongoDB controller would recreate the StatefulSet and the claim of a standalone which still exists
+	_, err = generateK8sDynamicClient().Resource(mongoDBStandaloneResource).Namespace(cr.Namespace).Get(context.TODO(), standalone, metav1.GetOptions{})
+	if err == nil {
+		logger.Info("Waiting for the MongoDB standalone resource to be deleted before adopting its volume", "Standalone", standalone)
+		return false, nil
+	}
+	if !errors.IsNotFound(err) {
+		return false, err
+	}
+	standaloneName := fmt.Sprintf("%s-%s", standalone, "standalone")
+	_, err = GetStateFulSet(cr.Namespace, standaloneName)
+	if err == nil {
+		logger.Info("Waiting for the MongoDB standalone to be deleted before adopting its volume", "Standalone", standalone)
+		return false, nil
+	}
+	if !errors.IsNotFound(err) {
+		return false, err
+	}
+	_, err = getPod(cr.Namespace, fmt.Sprintf("%s-0", standaloneName))
+	if err == nil {
+		logger.Info("Waiting for the MongoDB standalone pod to terminate", "Standalone", standalone)
+		return false, nil
+	}
+	if !errors.IsNotFound(err) {
+		return false, err
+	}
+	standaloneClaim, err := generateK8sClient().CoreV1().PersistentVolumeClaims(cr.Namespace).Get(context.TODO(), fmt.Sprintf("%s-%s-0", standaloneName, standaloneName), metav1.GetOptions{})
+	if err == nil {
+		return false, releaseStandaloneVolume(cr, standaloneClaim)
+	}
+	if !errors.IsNotFound(err) {
+		return false, err
+	}
+	return false, bindStandaloneVolume(cr, claimName)
+}
+
+// releaseStandaloneVolume is a method to retain the volume of MongoDB standalone and delete its claim
+func releaseStandaloneVolume(cr *opstreelabsinv1alpha1.MongoDBCluster, claim *corev1.PersistentVolumeClaim) error {
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Standalone Adoption")
+	if claim.Spec.VolumeName == "" {
+		return fmt.Errorf("claim %s of MongoDB standalone is not bound", claim.Name)
+	}
+	volume, err := generateK8sClient().CoreV1().PersistentVolumes().Get(context.TODO(), claim.Spec.VolumeName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	// the volume must survive the deletion of its claim, the original policy is restored once it is bound again
+	err = patchPersistentVolume(volume.Name, map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				adoptedByAnnotation:     fmt.Sprintf("%s/%s", cr.Namespace, cr.ObjectMeta.Name),
+				reclaimPolicyAnnotation: string(volume.Spec.PersistentVolumeReclaimPolicy),
+			},
+		},
+		"spec": map[string]interface{}{"persistentVolumeReclaimPolicy": corev1.PersistentVolumeReclaimRetain},
+	})
+	if err != nil {
+		return err
+	}
+	err = generateK8sClient().CoreV1().PersistentVolumeClaims(cr.Namespace).Delete(context.TODO(), claim.Name, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		logger.Error(err, "Unable to delete the claim of MongoDB standalone", "Claim", claim.Name)
+		return err
+	}
+	logger.Info("MongoDB standalone volume is released", "Volume", volume.Name)
+	return nil
+}
+
+// bindStandaloneVolume is a method to bind the released volume of MongoDB standalone to the claim of the first cluster member
+func bindStandaloneVolume(cr *opstreelabsinv1alpha1.MongoDBCluster, claimName string) error {
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Standalone Adoption")
+	volumes, err := generateK8sClient().CoreV1().PersistentVolumes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	var volume *corev1.PersistentVolume
+	for index := range volumes.Items {
+		if volumes.Items[index].Annotations[adoptedByAnnotation] == fmt.Sprintf("%s/%s", cr.Namespace, cr.ObjectMeta.Name) {
+			volume = &volumes.Items[index]
+			break
+		}
+	}
+	if volume == nil {
+		return fmt.Errorf("no volume found for MongoDB standalone %s", getAdoptedStandalone(cr))
+	}
+	err = patchPersistentVolume(volume.Name, getVolumePreBindPatch(cr.Namespace, claimName, volume.Annotations[reclaimPolicyAnnotation]))
+	if err != nil {
+		return err
+	}
+	claim := generatePersistentVolumeTemplate(getMongoDBClusterParams(cr).PVCParameters)
+	claim.Name = claimName
+	claim.Namespace = cr.Namespace
+	claim.Spec.VolumeName = volume.Name
+	storageClassName := volume.Spec.StorageClassName
+	claim.Spec.StorageClassName = &storageClassName
+	claim.Spec.Resources.Requests = corev1.ResourceList{corev1.ResourceStorage: volume.Spec.Capacity[corev1.ResourceStorage]}
+	_, err = generateK8sClient().CoreV1().PersistentVolumeClaims(cr.Namespace).Create(context.TODO(), &claim, metav1.CreateOptions{})
+	if err != nil {
+		logger.Error(err, "Unable to create the claim of the first MongoDB cluster member", "Claim", claimName)
+		return err
+	}
+	logger.Info("MongoDB standalone volume is adopted by the first cluster member", "Volume", volume.Name, "Claim", claimName)
+	return nil
+}
+
+// getVolumePreBindPatch is a method to get the patch pre-binding a released volume to a claim. The reference to the deleted claim keeps
+// the volume released, it is replaced by a reference to the new claim without uid, so the volume never becomes available to other claims
+func getVolumePreBindPatch(namespace string, claimName string, reclaimPolicy string) map[string]interface{} {
+	return map[string]interface{}{
+		"spec": map[string]interface{}{
+			"claimRef": map[string]interface{}{
+				"apiVersion":      "v1",
+				"kind":            "PersistentVolumeClaim",
+				"namespace":       namespace,
+				"name":            claimName,
+				"uid":             nil,
+				"resourceVersion": nil,
+			},
+			"persistentVolumeReclaimPolicy": reclaimPolicy,
+		},
+	}
+}
+
+// patchPersistentVolume is a method to apply a merge patch on a persistent volume
+func patchPersistentVolume(name string, patch map[string]interface{}) error {
+	logger := logGenerator(name, "", "PersistentVolume")
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	_, err = generateK8sClient().CoreV1().PersistentVolumes().Patch(context.TODO(), name, types.MergePatchType, data, metav1.PatchOptions{})
+	if err != nil {
+		logger.Error(err, "Unable to patch persistent volume")
+		return err
+	}
+	return nil
+}
+
+// getAdoptedStandalone is a method to get the name of the MongoDB standalone adopted by MongoDB cluster
+func getAdoptedStandalone(cr *opstreelabsinv1alpha1.MongoDBCluster) string {
+	return cr.ObjectMeta.Annotations[AdoptStandaloneAnnotation]
+}
+
+// getMongoClusterInitHost is a method to get the host used to initialize MongoDB cluster
+func getMongoClusterInitHost(cr *opstreelabsinv1alpha1.MongoDBCluster) string {
+	if getAdoptedStandalone(cr) != "" || bootstrapFromSnapshot(cr) {
+		// the replica set is initiated on the member holding the data of the standalone or of the snapshot
+		return mongogo.GetMongoNodeInfo(mongogo.MongoDBParameters{Namespace: cr.Namespace, Name: cr.ObjectMeta.Name}, 0)
+	}
+	return fmt.Sprintf("%s-%s.%s:27017", cr.ObjectMeta.Name, "cluster", cr.Namespace)
+}
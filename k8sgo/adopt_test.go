@@ -0,0 +1,61 @@
+package k8sgo
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+)
+
+func TestGetMongoClusterInitHost(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		bootstrap   *opstreelabsinv1alpha1.MongoDBBootstrap
+		want        string
+	}{
+		{name: "new cluster", want: "mongodb-cluster.default:27017"},
+		{name: "adopted standalone", annotations: map[string]string{AdoptStandaloneAnnotation: "mongodb"}, want: "mongodb-cluster-0.mongodb-cluster.default:27017"},
+		{name: "snapshot bootstrap", bootstrap: &opstreelabsinv1alpha1.MongoDBBootstrap{VolumeSnapshot: &opstreelabsinv1alpha1.BootstrapVolumeSnapshot{Name: "snapshot"}}, want: "mongodb-cluster-0.mongodb-cluster.default:27017"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := &opstreelabsinv1alpha1.MongoDBCluster{ObjectMeta: metav1.ObjectMeta{Name: "mongodb", Namespace: "default", Annotations: tt.annotations}}
+			cr.Spec.Bootstrap = tt.bootstrap
+			if got := getMongoClusterInitHost(cr); got != tt.want {
+				t.Errorf("got host %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetVolumePreBindPatch(t *testing.T) {
+	tests := []struct {
+		name          string
+		reclaimPolicy string
+	}{
+		{name: "delete reclaim policy", reclaimPolicy: "Delete"},
+		{name: "retain reclaim policy", reclaimPolicy: "Retain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch := getVolumePreBindPatch("default", "mongodb-cluster-mongodb-cluster-0", tt.reclaimPolicy)
+			spec := patch["spec"].(map[string]interface{})
+			want := map[string]interface{}{
+				"apiVersion":      "v1",
+				"kind":            "PersistentVolumeClaim",
+				"namespace":       "default",
+				"name":            "mongodb-cluster-mongodb-cluster-0",
+				"uid":             nil,
+				"resourceVersion": nil,
+			}
+			if !reflect.DeepEqual(spec["claimRef"], want) {
+				t.Errorf("got claim reference %v, want %v", spec["claimRef"], want)
+			}
+			if spec["persistentVolumeReclaimPolicy"] != tt.reclaimPolicy {
+				t.Errorf("got reclaim policy %v, want %s", spec["persistentVolumeReclaimPolicy"], tt.reclaimPolicy)
+			}
+		})
+	}
+}
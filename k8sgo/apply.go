@@ -0,0 +1,151 @@
+package k8sgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"os"
+	"path/filepath"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+	"strings"
+	"sync"
+)
+
+// fieldManager is the manager of the fields applied by the operator
+const fieldManager = "mongodb-operator"
+
+// legacyFieldManagers are the managers of the fields written by the operator before server-side apply, the client-side
+// updates were recorded with the name of the operator binary
+var legacyFieldManagers = map[string]bool{
+	filepath.Base(os.Args[0]): true,
+	"before-first-apply":      true,
+}
+
+// migratedObjects caches the objects whose managed fields are already migrated, indexed by resource, namespace and name
+var migratedObjects sync.Map
+
+// applyPatchFunc is the server-side apply patch call of a typed client
+type applyPatchFunc func(ctx context.Context, name string, data []byte, options metav1.PatchOptions) error
+
+// appliedObject is a generated object with its type information
+type appliedObject interface {
+	metav1.Object
+	runtime.Object
+}
+
+// serverSideApply is a method to apply a generated object with server-side apply, conflicts with other managers are returned
+func serverSideApply(object appliedObject, kind string, apply applyPatchFunc) error {
+	logger := logGenerator(object.GetName(), object.GetNamespace(), kind)
+	err := migrateLegacyManagedFields(object, kind)
+	if err != nil {
+		logger.Error(err, "Unable to migrate the managed fields of MongoDB object to server-side apply")
+		return err
+	}
+	data, err := json.Marshal(object)
+	if err != nil {
+		logger.Error(err, "Unable to serialize MongoDB object for apply")
+		return err
+	}
+	force := false
+	err = apply(context.TODO(), object.GetName(), data, metav1.PatchOptions{FieldManager: fieldManager, Force: &force})
+	if errors.IsConflict(err) {
+		// a field generated by the operator was changed by another manager, it is not taken over silently
+		logger.Error(err, "Generated fields of MongoDB object are managed by another manager, revert the change or remove the field from this manager")
+		return err
+	}
+	if err != nil {
+		logger.Error(err, "MongoDB object apply failed")
+		return err
+	}
+	logger.Info("MongoDB object apply was successful")
+	return nil
+}
+
+// migrateLegacyManagedFields is a method to transfer the fields written by the client-side updates of the operator to its apply
+// manager, so fields which are not generated anymore are removed by the next apply instead of staying with the update manager
+func migrateLegacyManagedFields(object appliedObject, kind string) error {
+	resource := object.GetObjectKind().GroupVersionKind().GroupVersion().WithResource(strings.ToLower(kind) + "s")
+	key := fmt.Sprintf("%s/%s/%s", resource.String(), object.GetNamespace(), object.GetName())
+	if _, ok := migratedObjects.Load(key); ok {
+		return nil
+	}
+	client := generateK8sDynamicClient().Resource(resource).Namespace(object.GetNamespace())
+	current, err := client.Get(context.TODO(), object.GetName(), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	managedFields, changed, err := upgradeManagedFields(current.GetManagedFields())
+	if err != nil {
+		return err
+	}
+	if changed {
+		// the resource version test makes the patch fail when the object changed since it was read
+		patch, err := json.Marshal([]map[string]interface{}{
+			{"op": "test", "path": "/metadata/resourceVersion", "value": current.GetResourceVersion()},
+			{"op": "replace", "path": "/metadata/managedFields", "value": managedFields},
+		})
+		if err != nil {
+			return err
+		}
+		_, err = client.Patch(context.TODO(), object.GetName(), types.JSONPatchType, patch, metav1.PatchOptions{})
+		if err != nil {
+			return err
+		}
+		logger := logGenerator(object.GetName(), object.GetNamespace(), kind)
+		logger.Info("Managed fields of MongoDB object are migrated to server-side apply")
+	}
+	migratedObjects.Store(key, true)
+	return nil
+}
+
+// upgradeManagedFields is a method to merge the field sets of the legacy update managers into the apply manager of the operator
+func upgradeManagedFields(managedFields []metav1.ManagedFieldsEntry) ([]metav1.ManagedFieldsEntry, bool, error) {
+	var upgraded []metav1.ManagedFieldsEntry
+	var applied *metav1.ManagedFieldsEntry
+	fields := &fieldpath.Set{}
+	changed := false
+	for _, entry := range managedFields {
+		legacy := entry.Operation == metav1.ManagedFieldsOperationUpdate && entry.Subresource == "" && legacyFieldManagers[entry.Manager]
+		current := entry.Operation == metav1.ManagedFieldsOperationApply && entry.Manager == fieldManager
+		if !legacy && !current {
+			upgraded = append(upgraded, entry)
+			continue
+		}
+		if entry.FieldsV1 != nil {
+			set := &fieldpath.Set{}
+			if err := set.FromJSON(bytes.NewReader(entry.FieldsV1.Raw)); err != nil {
+				return nil, false, err
+			}
+			fields = fields.Union(set)
+		}
+		if legacy {
+			changed = true
+		}
+		if applied == nil || current {
+			entryCopy := entry
+			applied = &entryCopy
+		}
+	}
+	if !changed {
+		return managedFields, false, nil
+	}
+	raw, err := fields.ToJSON()
+	if err != nil {
+		return nil, false, err
+	}
+	now := metav1.Now()
+	applied.Manager = fieldManager
+	applied.Operation = metav1.ManagedFieldsOperationApply
+	applied.FieldsType = "FieldsV1"
+	applied.FieldsV1 = &metav1.FieldsV1{Raw: raw}
+	applied.Time = &now
+	return append(upgraded, *applied), true, nil
+}
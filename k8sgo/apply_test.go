@@ -0,0 +1,79 @@
+package k8sgo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUpgradeManagedFields(t *testing.T) {
+	legacyManager := filepath.Base(os.Args[0])
+	entry := func(manager string, operation metav1.ManagedFieldsOperationType, fields string) metav1.ManagedFieldsEntry {
+		return metav1.ManagedFieldsEntry{Manager: manager, Operation: operation, APIVersion: "v1", FieldsType: "FieldsV1", FieldsV1: &metav1.FieldsV1{Raw: []byte(fields)}}
+	}
+	tests := []struct {
+		name        string
+		entries     []metav1.ManagedFieldsEntry
+		wantChanged bool
+		want        map[string]string
+	}{
+		{
+			name:    "already applied",
+			entries: []metav1.ManagedFieldsEntry{entry(fieldManager, metav1.ManagedFieldsOperationApply, `{"f:spec":{"f:type":{}}}`)},
+			want:    map[string]string{fieldManager: `{"f:spec":{"f:type":{}}}`},
+		},
+		{
+			name:    "other managers are kept",
+			entries: []metav1.ManagedFieldsEntry{entry("kubectl-edit", metav1.ManagedFieldsOperationUpdate, `{"f:metadata":{"f:labels":{"f:team":{}}}}`)},
+			want:    map[string]string{"kubectl-edit": `{"f:metadata":{"f:labels":{"f:team":{}}}}`},
+		},
+		{
+			name:        "client-side update is migrated",
+			entries:     []metav1.ManagedFieldsEntry{entry(legacyManager, metav1.ManagedFieldsOperationUpdate, `{"f:spec":{"f:type":{}}}`)},
+			wantChanged: true,
+			want:        map[string]string{fieldManager: `{"f:spec":{"f:type":{}}}`},
+		},
+		{
+			name: "client-side update is merged in the apply manager",
+			entries: []metav1.ManagedFieldsEntry{
+				entry(legacyManager, metav1.ManagedFieldsOperationUpdate, `{"f:spec":{"f:selector":{}}}`),
+				entry(fieldManager, metav1.ManagedFieldsOperationApply, `{"f:spec":{"f:type":{}}}`),
+				entry("kube-controller-manager", metav1.ManagedFieldsOperationUpdate, `{"f:status":{}}`),
+			},
+			wantChanged: true,
+			want: map[string]string{
+				fieldManager:              `{"f:spec":{"f:selector":{},"f:type":{}}}`,
+				"kube-controller-manager": `{"f:status":{}}`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changed, err := upgradeManagedFields(tt.entries)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed != tt.wantChanged {
+				t.Errorf("got changed %v, want %v", changed, tt.wantChanged)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d managers, want %d", len(got), len(tt.want))
+			}
+			for _, entry := range got {
+				want, ok := tt.want[entry.Manager]
+				if !ok {
+					t.Errorf("unexpected manager %s", entry.Manager)
+					continue
+				}
+				if string(entry.FieldsV1.Raw) != want {
+					t.Errorf("got fields %s for %s, want %s", entry.FieldsV1.Raw, entry.Manager, want)
+				}
+				if entry.Manager == fieldManager && entry.Operation != metav1.ManagedFieldsOperationApply {
+					t.Errorf("got operation %s for %s", entry.Operation, entry.Manager)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,303 @@
+package k8sgo
+
+import (
+	"fmt"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"mongodb-operator/backup"
+	"mongodb-operator/metrics"
+	"mongodb-operator/mongo"
+	"strings"
+)
+
+const (
+	backupUser                     = "backup"
+	backupVolumeName               = "backup"
+	backupMountPath                = "/backup"
+	defaultUploaderImage           = "minio/mc:RELEASE.2022-08-11T00-30-48Z"
+	defaultChunkIntervalSeconds    = 60
+	defaultBaseBackupIntervalHours = 24
+)
+
+// oplogArchiverScript takes a base backup on start and every BASE_BACKUP_INTERVAL_HOURS, and dumps contiguous oplog chunks in between
+const oplogArchiverScript = `set -e
+mkdir -p /backup/tmp /backup/outbox/base /backup/outbox/oplog
+URI="mongodb://${BACKUP_USER}:${BACKUP_PASSWORD}@${MONGO_HOSTS}/?replicaSet=${REPLICA_SET}&authSource=admin&readPreference=secondaryPreferred"
+last_base=0
+from=""
+while true; do
+  now=$(date +%s)
+  if [ $((now - last_base)) -ge $((BASE_BACKUP_INTERVAL_HOURS * 3600)) ]; then
+    [ -n "$from" ] || from=$now
+    mongodump --uri="$URI" --oplog --gzip --archive=/backup/tmp/base.archive.gz
+    last_base=$(date +%s)
+    mv /backup/tmp/base.archive.gz /backup/outbox/base/${last_base}.archive.gz
+  fi
+  sleep ${CHUNK_INTERVAL_SECONDS}
+  to=$(date +%s)
+  mongodump --uri="$URI" --db=local --collection=oplog.rs --gzip --out=/backup/tmp/oplog \
+    --query="{\"ts\":{\"\$gte\":{\"\$timestamp\":{\"t\":${from},\"i\":0}},\"\$lt\":{\"\$timestamp\":{\"t\":${to},\"i\":0}}}}"
+  mv /backup/tmp/oplog/local/oplog.rs.bson.gz /backup/outbox/oplog/${from}-${to}.bson.gz
+  rm -rf /backup/tmp/oplog
+  from=$to
+done
+`
+
+// backupUploaderScript ships the finished backup files to the object storage
+const backupUploaderScript = `mc alias set store "${S3_ENDPOINT}" "${S3_ACCESS_KEY}" "${S3_SECRET_KEY}" >/dev/null
+while true; do
+  for dir in base oplog; do
+    for file in /backup/outbox/${dir}/*; do
+      [ -f "$file" ] || continue
+      mc cp "$file" "store/${S3_BUCKET}/${S3_PREFIX}/${dir}/" && rm -f "$file"
+    done
+  done
+  sleep 10
+done
+`
+
+// CreateMongoClusterBackupSecret is a method to create secret for the backup user
+func CreateMongoClusterBackupSecret(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
+	defer metrics.StepTimer("cluster_backup_secret")()
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "Secret")
+	appName := fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster-backup")
+	labels := map[string]string{
+		"app":           appName,
+		"mongodb_setup": "cluster",
+		"role":          "cluster",
+	}
+	params := secretsParameters{
+		SecretsMeta: generateObjectMetaInformation(appName, cr.Namespace, labels, generateAnnotations(), getMongoDBClusterMetadata(cr, "")),
+		OwnerDef:    mongoClusterAsOwner(cr),
+		Namespace:   cr.Namespace,
+		Labels:      labels,
+		Annotations: generateAnnotations(),
+		Password:    generatePassword(defaultPasswordLength, ""),
+		Name:        appName,
+	}
+	err := CreateSecret(params)
+	if err != nil {
+		logger.Error(err, "Cannot create mongodb backup secret for cluster")
+		return err
+	}
+	return nil
+}
+
+// CheckMongoDBClusterBackupUser is a method to check if backup user exists in MongoDB cluster
+func CheckMongoDBClusterBackupUser(cr *opstreelabsinv1alpha1.MongoDBCluster) bool {
+	userName := backupUser
+	mongoParams := mongogo.MongoDBParameters{
+		MongoURL:  getMongoClusterURL(cr, getMongoClusterAdminPassword(cr)),
+		Namespace: cr.Namespace,
+		Name:      cr.ObjectMeta.Name,
+		UserName:  &userName,
+		SetupType: "cluster",
+	}
+	output, err := mongogo.GetMongoDBUser(mongoParams)
+	if err != nil {
+		return false
+	}
+	return output
+}
+
+// CreateMongoDBClusterBackupUser is a method to create the backup user in MongoDB cluster
+func CreateMongoDBClusterBackupUser(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
+	defer metrics.StepTimer("cluster_backup_user")()
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Backup User")
+	mongoParams := mongogo.MongoDBParameters{
+		MongoURL:  getMongoClusterURL(cr, getMongoClusterAdminPassword(cr)),
+		Namespace: cr.Namespace,
+		Name:      cr.ObjectMeta.Name,
+		Password:  getProviderPassword(cr.ObjectMeta.Name, cr.Namespace, getMongoClusterBackupCredentialProvider(cr)),
+		SetupType: "cluster",
+	}
+	err := mongogo.CreateBackupUser(mongoParams)
+	if err != nil {
+		logger.Error(err, "Unable to create backup user in MongoDB cluster")
+		return err
+	}
+	logger.Info("Successfully created the backup user in MongoDB cluster")
+	return nil
+}
+
+// CreateOrDeleteMongoClusterPITR is a method to create the oplog archiver of MongoDB cluster, or delete it when disabled or during a restore
+func CreateOrDeleteMongoClusterPITR(cr *opstreelabsinv1alpha1.MongoDBCluster, restoring bool) error {
+	defer metrics.StepTimer("cluster_pitr")()
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "Deployment")
+	// the archiver would dump the oplog of the restore and take a base backup of a partially restored cluster
+	if !pitrEnabled(cr) || restoring {
+		return deleteDeployment(cr.Namespace, fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster-pitr"))
+	}
+	err := CreateOrUpdateDeployment(generateOplogArchiverDef(cr))
+	if err != nil {
+		logger.Error(err, "Cannot create oplog archiver for MongoDB cluster")
+		return err
+	}
+	return nil
+}
+
+// CheckMongoClusterPITRStopped is a method to check that the oplog archiver of MongoDB cluster is not running
+func CheckMongoClusterPITRStopped(cr *opstreelabsinv1alpha1.MongoDBCluster) (bool, error) {
+	_, err := getDeployment(cr.Namespace, fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster-pitr"))
+	if errors.IsNotFound(err) {
+		return true, nil
+	}
+	return false, err
+}
+
+// GetMongoClusterRecoverableWindow is a method to get the recoverable time window of MongoDB cluster from the object storage
+func GetMongoClusterRecoverableWindow(cr *opstreelabsinv1alpha1.MongoDBCluster) (*opstreelabsinv1alpha1.MongoDBPITRStatus, error) {
+	defer metrics.StepTimer("cluster_pitr_window")()
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB PITR")
+	if !pitrEnabled(cr) {
+		return nil, nil
+	}
+	prefix := getBackupPrefix(cr)
+	keys, err := getBackupStorage(cr).ListObjects(prefix + "/")
+	if err != nil {
+		logger.Error(err, "Unable to list the archived backups of MongoDB cluster")
+		return nil, err
+	}
+	window, ok := backup.RecoverableWindow(prefix, keys)
+	if !ok {
+		return &opstreelabsinv1alpha1.MongoDBPITRStatus{}, nil
+	}
+	from, to, latest := metav1.NewTime(window.From), metav1.NewTime(window.To), metav1.NewTime(window.LatestBaseBackup)
+	return &opstreelabsinv1alpha1.MongoDBPITRStatus{RecoverableFrom: &from, RecoverableTo: &to, LatestBaseBackup: &latest}, nil
+}
+
+// pitrEnabled is a method to check if oplog archiving is enabled for MongoDB cluster
+func pitrEnabled(cr *opstreelabsinv1alpha1.MongoDBCluster) bool {
+	return cr.Spec.Backup != nil && cr.Spec.Backup.Storage != nil && cr.Spec.Backup.PITR != nil && cr.Spec.Backup.PITR.Enabled
+}
+
+// getBackupPrefix is a method to get the object storage prefix of MongoDB cluster backups
+func getBackupPrefix(cr *opstreelabsinv1alpha1.MongoDBCluster) string {
+	if cr.Spec.Backup.Storage.Prefix != "" {
+		return strings.Trim(cr.Spec.Backup.Storage.Prefix, "/")
+	}
+	return fmt.Sprintf("%s/%s", cr.Namespace, cr.ObjectMeta.Name)
+}
+
+// getBackupStorage is a method to get the object storage of MongoDB cluster backups
+func getBackupStorage(cr *opstreelabsinv1alpha1.MongoDBCluster) backup.Storage {
+	storage := cr.Spec.Backup.Storage
+	accessKey, secretKey := getBackupStorageCredentialProviders(cr.Namespace, storage)
+	return backup.Storage{
+		Endpoint:  storage.Endpoint,
+		Region:    storage.Region,
+		Bucket:    storage.Bucket,
+		AccessKey: getProviderPassword(cr.ObjectMeta.Name, cr.Namespace, accessKey),
+		SecretKey: getProviderPassword(cr.ObjectMeta.Name, cr.Namespace, secretKey),
+	}
+}
+
+// getBackupStorageEnv is a method to get the environment variables of the object storage for backup containers
+func getBackupStorageEnv(namespace string, storage *opstreelabsinv1alpha1.BackupStorage, prefix string) []corev1.EnvVar {
+	accessKey, secretKey := getBackupStorageCredentialProviders(namespace, storage)
+	return []corev1.EnvVar{
+		{Name: "S3_ENDPOINT", Value: storage.Endpoint},
+		{Name: "S3_BUCKET", Value: storage.Bucket},
+		{Name: "S3_PREFIX", Value: prefix},
+		accessKey.GetPodCredential("S3_ACCESS_KEY").Env,
+		secretKey.GetPodCredential("S3_SECRET_KEY").Env,
+	}
+}
+
+// getSecretKeyRef is a method to generate an environment variable source from a secret key
+func getSecretKeyRef(name string, key string) *corev1.EnvVarSource {
+	return &corev1.EnvVarSource{
+		SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: name},
+			Key:                  key,
+		},
+	}
+}
+
+// generateOplogArchiverDef is a method to generate the oplog archiver deployment of MongoDB cluster
+func generateOplogArchiverDef(cr *opstreelabsinv1alpha1.MongoDBCluster) *appsv1.Deployment {
+	appName := fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster-pitr")
+	labels := map[string]string{
+		"app":           appName,
+		"mongodb_setup": "cluster",
+		"role":          "pitr",
+	}
+	pitr := cr.Spec.Backup.PITR
+	image := cr.Spec.KubernetesConfig.Image
+	if pitr.Image != "" {
+		image = pitr.Image
+	}
+	uploaderImage := defaultUploaderImage
+	if pitr.UploaderImage != "" {
+		uploaderImage = pitr.UploaderImage
+	}
+	chunkInterval := int32(defaultChunkIntervalSeconds)
+	if pitr.ChunkIntervalSeconds != nil {
+		chunkInterval = *pitr.ChunkIntervalSeconds
+	}
+	baseInterval := int32(defaultBaseBackupIntervalHours)
+	if pitr.BaseBackupIntervalHours != nil {
+		baseInterval = *pitr.BaseBackupIntervalHours
+	}
+	resources := corev1.ResourceRequirements{}
+	if pitr.Resources != nil {
+		resources = *pitr.Resources
+	}
+	volumeMounts := []corev1.VolumeMount{{Name: backupVolumeName, MountPath: backupMountPath}}
+	replicas := int32(1)
+	deployment := &appsv1.Deployment{
+		TypeMeta:   generateMetaInformation("Deployment", "apps/v1"),
+		ObjectMeta: generateObjectMetaInformation(appName, cr.Namespace, labels, generateAnnotations(), getMongoDBClusterMetadata(cr, "")),
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: LabelSelectors(labels),
+			// two archivers would upload overlapping chunks
+			Strategy: appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      mergeMaps(getMongoDBClusterMetadata(cr, podObject).Labels, labels),
+					Annotations: getMongoDBClusterMetadata(cr, podObject).Annotations,
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:            "oplog-archiver",
+							Image:           image,
+							ImagePullPolicy: cr.Spec.KubernetesConfig.ImagePullPolicy,
+							Command:         []string{"/bin/sh", "-c", oplogArchiverScript},
+							Env: []corev1.EnvVar{
+								{Name: "MONGO_HOSTS", Value: getMongoClusterHosts(cr)},
+								{Name: "REPLICA_SET", Value: cr.ObjectMeta.Name},
+								{Name: "BACKUP_USER", Value: backupUser},
+								getMongoClusterBackupCredentialProvider(cr).GetPodCredential("BACKUP_PASSWORD").Env,
+								{Name: "CHUNK_INTERVAL_SECONDS", Value: fmt.Sprint(chunkInterval)},
+								{Name: "BASE_BACKUP_INTERVAL_HOURS", Value: fmt.Sprint(baseInterval)},
+							},
+							Resources:    resources,
+							VolumeMounts: volumeMounts,
+						},
+						{
+							Name:            "uploader",
+							Image:           uploaderImage,
+							ImagePullPolicy: cr.Spec.KubernetesConfig.ImagePullPolicy,
+							Command:         []string{"/bin/sh", "-c", backupUploaderScript},
+							Env:             getBackupStorageEnv(cr.Namespace, cr.Spec.Backup.Storage, getBackupPrefix(cr)),
+							VolumeMounts:    volumeMounts,
+						},
+					},
+					Volumes: []corev1.Volume{
+						{Name: backupVolumeName, VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+					},
+				},
+			},
+		},
+	}
+	if cr.Spec.KubernetesConfig.ImagePullSecret != nil {
+		deployment.Spec.Template.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: *cr.Spec.KubernetesConfig.ImagePullSecret}}
+	}
+	AddOwnerRefToObject(deployment, mongoClusterAsOwner(cr))
+	return deployment
+}
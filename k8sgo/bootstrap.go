@@ -0,0 +1,230 @@
+package k8sgo
+
+import (
+	"context"
+	"fmt"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"mongodb-operator/metrics"
+	"strings"
+)
+
+const (
+	// BootstrapPhaseRunning is the phase of a bootstrap loading data
+	BootstrapPhaseRunning = "Running"
+	// BootstrapPhaseCompleted is the phase of a bootstrap with all data loaded
+	BootstrapPhaseCompleted = "Completed"
+	// BootstrapPhaseFailed is the phase of a bootstrap which failed to load data
+	BootstrapPhaseFailed = "Failed"
+	bootstrapMongodPort  = 27099
+)
+
+// bootstrapSnapshotScript drops the replica set configuration of the source cluster from a volume created from a snapshot
+const bootstrapSnapshotScript = `set -e
+[ -f /data/db/WiredTiger ] || exit 0
+mongod --dbpath /data/db --port %[1]d --bind_ip 127.0.0.1 --fork --logpath /tmp/bootstrap.log
+SHELL_BIN=mongosh
+command -v mongosh >/dev/null 2>&1 || SHELL_BIN=mongo
+$SHELL_BIN --quiet --port %[1]d --eval "var c = db.getSiblingDB('local').system.replset.findOne(); if (c && !c.members.some(function(m) { return m.host.indexOf('${SERVICE_DOMAIN}') >= 0; })) { db.getSiblingDB('local').dropDatabase(); print('Dropped the replica set configuration of the source cluster'); }"
+mongod --dbpath /data/db --shutdown
+`
+
+// bootstrapBackupDownloadScript downloads the archive to load, the latest base backup when no key is given
+const bootstrapBackupDownloadScript = `set -e
+mc alias set store "${S3_ENDPOINT}" "${S3_ACCESS_KEY}" "${S3_SECRET_KEY}" >/dev/null
+if [ -z "$ARCHIVE_KEY" ]; then
+  latest=$(mc ls "store/${S3_BUCKET}/${S3_PREFIX}/base/" | awk '{print $NF}' | sort -n | tail -1)
+  [ -n "$latest" ] || { echo "No base backup found"; exit 1; }
+  ARCHIVE_KEY="${S3_PREFIX}/base/${latest}"
+fi
+mc cp "store/${S3_BUCKET}/${ARCHIVE_KEY}" /restore/base.archive.gz
+`
+
+// bootstrapBackupLoadScript loads the archive, users of the source are excluded so the operator credentials keep working
+const bootstrapBackupLoadScript = `set -e
+[ -z "$MONGO_PASSWORD_FILE" ] || MONGO_PASSWORD=$(cat "$MONGO_PASSWORD_FILE")
+URI="mongodb://${MONGO_USER}:${MONGO_PASSWORD}@${MONGO_HOSTS}/?replicaSet=${REPLICA_SET}&authSource=admin"
+mongorestore --uri="$URI" --drop --gzip --archive=/restore/base.archive.gz --oplogReplay --nsExclude='admin.system.*'
+`
+
+// bootstrapExternalScript copies a consistent dump of the external replica set, up to the end of the dump.
+// The members are not added to the external replica set for an initial sync, that would reconfigure a replica set
+// which is not managed by the operator and require it to share the keyfile of the cluster.
+const bootstrapExternalScript = `set -e
+[ -z "$MONGO_PASSWORD_FILE" ] || MONGO_PASSWORD=$(cat "$MONGO_PASSWORD_FILE")
+URI="mongodb://${MONGO_USER}:${MONGO_PASSWORD}@${MONGO_HOSTS}/?replicaSet=${REPLICA_SET}&authSource=admin"
+mongodump --uri="$SOURCE_URI" --readPreference=secondaryPreferred --oplog --gzip --archive | \
+  mongorestore --uri="$URI" --drop --gzip --archive --oplogReplay --nsExclude='admin.system.*'
+`
+
+// BootstrapMongoDBCluster is a method to load the bootstrap data in an initialized MongoDB cluster and return the bootstrap state
+func BootstrapMongoDBCluster(cr *opstreelabsinv1alpha1.MongoDBCluster) (*opstreelabsinv1alpha1.MongoDBBootstrapStatus, error) {
+	defer metrics.StepTimer("cluster_bootstrap")()
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Bootstrap")
+	if cr.Spec.Bootstrap == nil {
+		return cr.Status.Bootstrap, nil
+	}
+	if cr.Status.Bootstrap != nil && cr.Status.Bootstrap.Phase == BootstrapPhaseCompleted {
+		return cr.Status.Bootstrap, nil
+	}
+	if err := validateBootstrap(cr.Spec.Bootstrap); err != nil {
+		return &opstreelabsinv1alpha1.MongoDBBootstrapStatus{Phase: BootstrapPhaseFailed, Message: err.Error()}, nil
+	}
+	if cr.Spec.Bootstrap.VolumeSnapshot != nil {
+		// the data is already in the volume of the first member, the other members sync from it
+		return &opstreelabsinv1alpha1.MongoDBBootstrapStatus{Phase: BootstrapPhaseCompleted, Message: fmt.Sprintf("Loaded from VolumeSnapshot %s", cr.Spec.Bootstrap.VolumeSnapshot.Name)}, nil
+	}
+	job, err := CreateJobIfNotExist(generateBootstrapJobDef(cr))
+	if err != nil {
+		logger.Error(err, "Cannot create bootstrap job for MongoDB cluster")
+		return nil, err
+	}
+	finished, succeeded := JobFinished(job)
+	switch {
+	case !finished:
+		return &opstreelabsinv1alpha1.MongoDBBootstrapStatus{Phase: BootstrapPhaseRunning, Message: fmt.Sprintf("Loading data with job %s", job.Name)}, nil
+	case succeeded:
+		logger.Info("MongoDB cluster data is loaded from the bootstrap source")
+		return &opstreelabsinv1alpha1.MongoDBBootstrapStatus{Phase: BootstrapPhaseCompleted, Message: "Data loaded from the bootstrap source"}, nil
+	}
+	return &opstreelabsinv1alpha1.MongoDBBootstrapStatus{Phase: BootstrapPhaseFailed, Message: fmt.Sprintf("Bootstrap job %s failed, check the logs of the job pods", job.Name)}, nil
+}
+
+// validateBootstrap is a method to check that exactly one bootstrap source is defined
+func validateBootstrap(bootstrap *opstreelabsinv1alpha1.MongoDBBootstrap) error {
+	sources := 0
+	if bootstrap.Backup != nil {
+		sources++
+	}
+	if bootstrap.VolumeSnapshot != nil {
+		sources++
+	}
+	if bootstrap.ExternalReplicaSet != nil {
+		sources++
+	}
+	if sources != 1 {
+		return fmt.Errorf("exactly one bootstrap source must be defined, found %d", sources)
+	}
+	return nil
+}
+
+// bootstrapFromSnapshot is a method to check if MongoDB cluster is being seeded from a VolumeSnapshot
+func bootstrapFromSnapshot(cr *opstreelabsinv1alpha1.MongoDBCluster) bool {
+	if cr.Spec.Bootstrap == nil || cr.Spec.Bootstrap.VolumeSnapshot == nil {
+		return false
+	}
+	return cr.Status.Bootstrap == nil || cr.Status.Bootstrap.Phase != BootstrapPhaseCompleted
+}
+
+// createMongoClusterBootstrapVolume is a method to create the volume of the first member from the bootstrap VolumeSnapshot,
+// the replica set is initiated on it and the other members start empty and initial sync from it
+func createMongoClusterBootstrapVolume(cr *opstreelabsinv1alpha1.MongoDBCluster, params pvcParameters) error {
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "PersistentVolumeClaim")
+	if !bootstrapFromSnapshot(cr) || cr.Spec.Storage == nil {
+		return nil
+	}
+	appName := fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster")
+	claimName := fmt.Sprintf("%s-%s-%d", params.Name, appName, 0)
+	_, err := generateK8sClient().CoreV1().PersistentVolumeClaims(cr.Namespace).Get(context.TODO(), claimName, metav1.GetOptions{})
+	if err == nil || !errors.IsNotFound(err) {
+		return err
+	}
+	claim := generatePersistentVolumeTemplate(params)
+	claim.Name = claimName
+	claim.Namespace = cr.Namespace
+	claim.Spec.DataSource = &corev1.TypedLocalObjectReference{
+		APIGroup: &volumeSnapshotResource.Group,
+		Kind:     "VolumeSnapshot",
+		Name:     cr.Spec.Bootstrap.VolumeSnapshot.Name,
+	}
+	_, err = generateK8sClient().CoreV1().PersistentVolumeClaims(cr.Namespace).Create(context.TODO(), &claim, metav1.CreateOptions{})
+	if err != nil {
+		logger.Error(err, "MongoDB member volume creation from bootstrap snapshot is failed", "Claim", claimName)
+		return err
+	}
+	logger.Info("MongoDB member volume is created from bootstrap snapshot", "Claim", claimName, "Snapshot", cr.Spec.Bootstrap.VolumeSnapshot.Name)
+	return nil
+}
+
+// getBootstrapInitContainer is a method to generate the init container cleaning the volume created from a snapshot of another cluster
+func getBootstrapInitContainer(cr *opstreelabsinv1alpha1.MongoDBCluster) corev1.Container {
+	appName := fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster")
+	return corev1.Container{
+		Name:            "bootstrap",
+		Image:           cr.Spec.KubernetesConfig.Image,
+		ImagePullPolicy: cr.Spec.KubernetesConfig.ImagePullPolicy,
+		Command:         []string{"/bin/sh", "-c", fmt.Sprintf(bootstrapSnapshotScript, bootstrapMongodPort)},
+		Env:             []corev1.EnvVar{{Name: "SERVICE_DOMAIN", Value: fmt.Sprintf("%s.%s", appName, cr.Namespace)}},
+		VolumeMounts:    []corev1.VolumeMount{{Name: appName, MountPath: "/data/db"}},
+	}
+}
+
+// generateBootstrapJobDef is a method to generate the job loading the bootstrap data in MongoDB cluster
+func generateBootstrapJobDef(cr *opstreelabsinv1alpha1.MongoDBCluster) *batchv1.Job {
+	appName := fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster-bootstrap")
+	labels := map[string]string{
+		"app":           appName,
+		"mongodb_setup": "cluster",
+		"role":          "bootstrap",
+	}
+	adminCredential := getMongoClusterAdminCredentialProvider(cr).GetPodCredential("MONGO_PASSWORD")
+	clusterEnv := []corev1.EnvVar{
+		{Name: "MONGO_HOSTS", Value: getMongoClusterHosts(cr)},
+		{Name: "REPLICA_SET", Value: cr.ObjectMeta.Name},
+		{Name: "MONGO_USER", Value: cr.Spec.MongoDBSecurity.MongoDBAdminUser},
+		adminCredential.Env,
+	}
+	podSpec := corev1.PodSpec{RestartPolicy: corev1.RestartPolicyNever}
+	if source := cr.Spec.Bootstrap.Backup; source != nil {
+		volumeMounts := []corev1.VolumeMount{{Name: restoreVolumeName, MountPath: "/restore"}}
+		podSpec.Volumes = []corev1.Volume{{Name: restoreVolumeName, VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}}
+		podSpec.InitContainers = []corev1.Container{
+			{
+				Name:            "download",
+				Image:           defaultUploaderImage,
+				ImagePullPolicy: cr.Spec.KubernetesConfig.ImagePullPolicy,
+				Command:         []string{"/bin/sh", "-c", bootstrapBackupDownloadScript},
+				Env:             append(getBackupStorageEnv(cr.Namespace, &source.Storage, strings.Trim(source.Storage.Prefix, "/")), corev1.EnvVar{Name: "ARCHIVE_KEY", Value: source.Key}),
+				VolumeMounts:    volumeMounts,
+			},
+		}
+		podSpec.Containers = []corev1.Container{
+			{
+				Name:            "load",
+				Image:           cr.Spec.KubernetesConfig.Image,
+				ImagePullPolicy: cr.Spec.KubernetesConfig.ImagePullPolicy,
+				Command:         []string{"/bin/sh", "-c", bootstrapBackupLoadScript},
+				Env:             clusterEnv,
+				VolumeMounts:    volumeMounts,
+			},
+		}
+	}
+	if source := cr.Spec.Bootstrap.ExternalReplicaSet; source != nil {
+		podSpec.Containers = []corev1.Container{
+			{
+				Name:            "load",
+				Image:           cr.Spec.KubernetesConfig.Image,
+				ImagePullPolicy: cr.Spec.KubernetesConfig.ImagePullPolicy,
+				Command:         []string{"/bin/sh", "-c", bootstrapExternalScript},
+				Env:             append(clusterEnv, corev1.EnvVar{Name: "SOURCE_URI", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &source.URISecret}}),
+			},
+		}
+	}
+	if cr.Spec.KubernetesConfig.ImagePullSecret != nil {
+		podSpec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: *cr.Spec.KubernetesConfig.ImagePullSecret}}
+	}
+	backoffLimit := int32(2)
+	job := &batchv1.Job{
+		TypeMeta:   generateMetaInformation("Job", "batch/v1"),
+		ObjectMeta: generateObjectMetaInformation(appName, cr.Namespace, labels, generateAnnotations(), getMongoDBClusterMetadata(cr, "")),
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template:     corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Annotations: getJobPodAnnotations(adminCredential)}, Spec: podSpec},
+		},
+	}
+	AddOwnerRefToObject(job, mongoClusterAsOwner(cr))
+	return job
+}
@@ -0,0 +1,58 @@
+package k8sgo
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+)
+
+func TestValidateBootstrap(t *testing.T) {
+	backup := &opstreelabsinv1alpha1.BootstrapBackup{}
+	snapshot := &opstreelabsinv1alpha1.BootstrapVolumeSnapshot{Name: "mongodb-cluster-1-20211215100000"}
+	external := &opstreelabsinv1alpha1.BootstrapExternalReplicaSet{URISecret: corev1.SecretKeySelector{Key: "uri"}}
+	tests := []struct {
+		name      string
+		bootstrap *opstreelabsinv1alpha1.MongoDBBootstrap
+		wantErr   bool
+	}{
+		{name: "no source", bootstrap: &opstreelabsinv1alpha1.MongoDBBootstrap{}, wantErr: true},
+		{name: "backup", bootstrap: &opstreelabsinv1alpha1.MongoDBBootstrap{Backup: backup}},
+		{name: "snapshot", bootstrap: &opstreelabsinv1alpha1.MongoDBBootstrap{VolumeSnapshot: snapshot}},
+		{name: "external replica set", bootstrap: &opstreelabsinv1alpha1.MongoDBBootstrap{ExternalReplicaSet: external}},
+		{name: "two sources", bootstrap: &opstreelabsinv1alpha1.MongoDBBootstrap{Backup: backup, VolumeSnapshot: snapshot}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateBootstrap(tt.bootstrap); (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBootstrapFromSnapshot(t *testing.T) {
+	snapshot := &opstreelabsinv1alpha1.MongoDBBootstrap{VolumeSnapshot: &opstreelabsinv1alpha1.BootstrapVolumeSnapshot{Name: "snapshot"}}
+	tests := []struct {
+		name      string
+		bootstrap *opstreelabsinv1alpha1.MongoDBBootstrap
+		status    *opstreelabsinv1alpha1.MongoDBBootstrapStatus
+		want      bool
+	}{
+		{name: "no bootstrap"},
+		{name: "backup", bootstrap: &opstreelabsinv1alpha1.MongoDBBootstrap{Backup: &opstreelabsinv1alpha1.BootstrapBackup{}}},
+		{name: "snapshot not started", bootstrap: snapshot, want: true},
+		{name: "snapshot running", bootstrap: snapshot, status: &opstreelabsinv1alpha1.MongoDBBootstrapStatus{Phase: BootstrapPhaseRunning}, want: true},
+		{name: "snapshot completed", bootstrap: snapshot, status: &opstreelabsinv1alpha1.MongoDBBootstrapStatus{Phase: BootstrapPhaseCompleted}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := &opstreelabsinv1alpha1.MongoDBCluster{}
+			cr.Spec.Bootstrap = tt.bootstrap
+			cr.Status.Bootstrap = tt.status
+			if got := bootstrapFromSnapshot(cr); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
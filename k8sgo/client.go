@@ -1,6 +1,7 @@
 package k8sgo
 
 import (
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -19,6 +20,19 @@ func generateK8sClient() *kubernetes.Clientset {
 	return clientset
 }
 
+// generateK8sDynamicClient create dynamic client for kubernetes, used for resources without typed clients
+func generateK8sDynamicClient() dynamic.Interface {
+	config, err := generateK8sConfig()
+	if err != nil {
+		panic(err.Error())
+	}
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		panic(err.Error())
+	}
+	return client
+}
+
 // generateK8sConfig will load the kube config file
 func generateK8sConfig() (*rest.Config, error) {
 	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
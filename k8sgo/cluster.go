@@ -2,12 +2,14 @@ package k8sgo
 
 import (
 	"fmt"
-	"github.com/thanhpk/randstr"
+	corev1 "k8s.io/api/core/v1"
 	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"mongodb-operator/metrics"
 )
 
 // CreateMongoClusterService is a method to create service for mongodb cluster
 func CreateMongoClusterService(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
+	defer metrics.StepTimer("cluster_service")()
 	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "Service")
 	appName := fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster")
 	labels := map[string]string{
@@ -16,7 +18,7 @@ func CreateMongoClusterService(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
 		"role":          "cluster",
 	}
 	params := serviceParameters{
-		ServiceMeta:     generateObjectMetaInformation(appName, cr.Namespace, labels, generateAnnotations()),
+		ServiceMeta:     generateObjectMetaInformation(appName, cr.Namespace, labels, generateAnnotations(), getMongoDBClusterMetadata(cr, serviceObject)),
 		OwnerDef:        mongoClusterAsOwner(cr),
 		Namespace:       cr.Namespace,
 		Labels:          labels,
@@ -24,6 +26,7 @@ func CreateMongoClusterService(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
 		HeadlessService: true,
 		Port:            mongoDBPort,
 		PortName:        "mongo",
+		PublishNotReady: true,
 	}
 	err := CreateOrUpdateService(params)
 	if err != nil {
@@ -33,8 +36,55 @@ func CreateMongoClusterService(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
 	return nil
 }
 
+// CreateMongoClusterClientService is a method to create the client and primary services for mongodb cluster
+func CreateMongoClusterClientService(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
+	defer metrics.StepTimer("cluster_client_service")()
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "Service")
+	appName := fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster")
+	labels := map[string]string{
+		"app":           appName,
+		"mongodb_setup": "cluster",
+		"role":          "cluster",
+	}
+	if cr.Spec.Service != nil {
+		clientParams := serviceParameters{
+			ServiceMeta: generateObjectMetaInformation(fmt.Sprintf("%s-%s", appName, "client"), cr.Namespace, labels, generateAnnotations(), getMongoDBClusterMetadata(cr, serviceObject)),
+			OwnerDef:    mongoClusterAsOwner(cr),
+			Namespace:   cr.Namespace,
+			Labels:      labels,
+			Annotations: generateAnnotations(),
+			Port:        mongoDBPort,
+			PortName:    "mongo",
+		}
+		applyServiceSpec(&clientParams, cr.Spec.Service)
+		err := CreateOrUpdateService(clientParams)
+		if err != nil {
+			logger.Error(err, "Cannot create cluster client Service for MongoDB")
+			return err
+		}
+	}
+	primaryParams := serviceParameters{
+		ServiceMeta: generateObjectMetaInformation(fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "primary"), cr.Namespace, labels, generateAnnotations(), getMongoDBClusterMetadata(cr, serviceObject)),
+		OwnerDef:    mongoClusterAsOwner(cr),
+		Namespace:   cr.Namespace,
+		Labels:      labels,
+		Selector:    mergeMaps(labels, map[string]string{roleLabel: primaryRole}),
+		Annotations: generateAnnotations(),
+		Port:        mongoDBPort,
+		PortName:    "mongo",
+	}
+	applyServiceSpec(&primaryParams, cr.Spec.PrimaryService)
+	err := CreateOrUpdateService(primaryParams)
+	if err != nil {
+		logger.Error(err, "Cannot create cluster primary Service for MongoDB")
+		return err
+	}
+	return nil
+}
+
 // CreateMongoClusterMonitoringService is a method to create a monitoring service for mongodb cluster
 func CreateMongoClusterMonitoringService(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
+	defer metrics.StepTimer("cluster_monitoring_service")()
 	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "Service")
 	appName := fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster")
 	labels := map[string]string{
@@ -43,7 +93,7 @@ func CreateMongoClusterMonitoringService(cr *opstreelabsinv1alpha1.MongoDBCluste
 		"role":          "cluster",
 	}
 	monitoringParams := serviceParameters{
-		ServiceMeta:     generateObjectMetaInformation(fmt.Sprintf("%s-%s", appName, "metrics"), cr.Namespace, labels, generateAnnotations()),
+		ServiceMeta:     generateObjectMetaInformation(fmt.Sprintf("%s-%s", appName, "metrics"), cr.Namespace, labels, generateAnnotations(), getMongoDBClusterMetadata(cr, serviceObject)),
 		OwnerDef:        mongoClusterAsOwner(cr),
 		Namespace:       cr.Namespace,
 		Labels:          labels,
@@ -62,8 +112,40 @@ func CreateMongoClusterMonitoringService(cr *opstreelabsinv1alpha1.MongoDBCluste
 
 // CreateMongoClusterSetup is a method to create cluster statefulset for MongoDB
 func CreateMongoClusterSetup(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
+	defer metrics.StepTimer("cluster_statefulset")()
 	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "StatefulSet")
-	err := CreateOrUpdateStateFul(getMongoDBClusterParams(cr))
+	err := validateImageFlavor(cr.Spec.KubernetesConfig.ImageFlavor, cr.Spec.MongoDBAdditionalConfig)
+	if err != nil {
+		logger.Error(err, "Invalid configuration for the image flavor of MongoDB")
+		return err
+	}
+	err = validateVaultCredential(cr.Spec.KubernetesConfig.ImageFlavor, cr.Spec.MongoDBSecurity)
+	if err != nil {
+		logger.Error(err, "Invalid vault configuration of MongoDB")
+		return err
+	}
+	params := getMongoDBClusterParams(cr)
+	err = createMongodConfig(cr.Spec.MongodConfig, &params)
+	if err != nil {
+		logger.Error(err, "Cannot create mongod configuration for MongoDB")
+		return err
+	}
+	if params.ContainerParams.KeyfileSecret != nil {
+		err = CreateMongoClusterKeyfileSecret(cr)
+		if err != nil {
+			return err
+		}
+	}
+	err = createMongoClusterBootstrapVolume(cr, params.PVCParameters)
+	if err != nil {
+		return err
+	}
+	err = seedMongoClusterMembers(cr, params.PVCParameters)
+	if err != nil {
+		logger.Error(err, "Cannot seed new MongoDB members from snapshot")
+		return err
+	}
+	err = CreateOrUpdateStateFul(params)
 	if err != nil {
 		logger.Error(err, "Cannot create cluster StatefulSet for MongoDB")
 		return err
@@ -74,14 +156,27 @@ func CreateMongoClusterSetup(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
 			logger.Error(err, "Cannot create PodDisruptionBudget for MongoDB")
 			return err
 		}
+	} else {
+		err = DeletePodDisruption(cr.Namespace, fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster"))
+		if err != nil {
+			logger.Error(err, "Cannot delete PodDisruptionBudget for MongoDB")
+			return err
+		}
 	}
 	return nil
 }
 
 // CreateMongoClusterMonitoringSecret is a method to create secret for monitoring
 func CreateMongoClusterMonitoringSecret(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
+	defer metrics.StepTimer("cluster_monitoring_secret")()
 	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "Secret")
-	err := CreateSecret(getMongoDBClusterSecretParams(cr))
+	params := getMongoDBClusterSecretParams(cr)
+	standaloneSecret := fmt.Sprintf("%s-%s", getAdoptedStandalone(cr), "standalone-monitoring")
+	if getAdoptedStandalone(cr) != "" && CheckSecretExist(cr.Namespace, standaloneSecret) {
+		// the monitoring user of the standalone comes with the adopted data, so its password is kept
+		params.Password = getProviderPassword(cr.ObjectMeta.Name, cr.Namespace, &kubernetesSecretProvider{Namespace: cr.Namespace, SecretName: standaloneSecret, SecretKey: "password"})
+	}
+	err := CreateSecret(params)
 	if err != nil {
 		logger.Error(err, "Cannot create mongodb monitoring secret for cluster")
 		return err
@@ -91,7 +186,7 @@ func CreateMongoClusterMonitoringSecret(cr *opstreelabsinv1alpha1.MongoDBCluster
 
 // getMongoDBClusterSecretParams is a method to create secret for MongoDB Monitoring
 func getMongoDBClusterSecretParams(cr *opstreelabsinv1alpha1.MongoDBCluster) secretsParameters {
-	password := randstr.String(16)
+	password := generatePassword(defaultPasswordLength, "")
 	appName := fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster-monitoring")
 	labels := map[string]string{
 		"app":           appName,
@@ -99,7 +194,7 @@ func getMongoDBClusterSecretParams(cr *opstreelabsinv1alpha1.MongoDBCluster) sec
 		"role":          "cluster",
 	}
 	params := secretsParameters{
-		SecretsMeta: generateObjectMetaInformation(appName, cr.Namespace, labels, generateAnnotations()),
+		SecretsMeta: generateObjectMetaInformation(appName, cr.Namespace, labels, generateAnnotations(), getMongoDBClusterMetadata(cr, "")),
 		OwnerDef:    mongoClusterAsOwner(cr),
 		Namespace:   cr.Namespace,
 		Labels:      labels,
@@ -115,48 +210,81 @@ func getMongoDBClusterParams(cr *opstreelabsinv1alpha1.MongoDBCluster) statefulS
 	trueProperty := true
 	falseProperty := false
 	appName := fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster")
-	monitoringSecretName := fmt.Sprintf("%s-%s", appName, "monitoring")
 	labels := map[string]string{
 		"app":           appName,
 		"mongodb_setup": "cluster",
 		"role":          "cluster",
 	}
 	params := statefulSetParameters{
-		StatefulSetMeta: generateObjectMetaInformation(appName, cr.Namespace, labels, generateAnnotations()),
+		StatefulSetMeta: generateObjectMetaInformation(appName, cr.Namespace, labels, generateAnnotations(), getMongoDBClusterMetadata(cr, statefulSetObject)),
+		CommonMetadata:  getMongoDBClusterMetadata(cr, ""),
 		OwnerDef:        mongoClusterAsOwner(cr),
 		Namespace:       cr.Namespace,
 		ContainerParams: containerParameters{
 			Image:               cr.Spec.KubernetesConfig.Image,
 			ImagePullPolicy:     cr.Spec.KubernetesConfig.ImagePullPolicy,
 			Resources:           cr.Spec.KubernetesConfig.Resources,
+			LivenessProbe:       cr.Spec.KubernetesConfig.LivenessProbe,
+			ReadinessProbe:      cr.Spec.KubernetesConfig.ReadinessProbe,
+			StartupProbe:        cr.Spec.KubernetesConfig.StartupProbe,
+			ExtraVolumeMounts:   cr.Spec.KubernetesConfig.ExtraVolumeMounts,
+			ExtraEnv:            cr.Spec.KubernetesConfig.ExtraEnv,
+			SecurityContext:     cr.Spec.KubernetesConfig.ContainerSecurityContext,
 			MongoReplicaSetName: &cr.ObjectMeta.Name,
 			MongoSetupType:      "cluster",
+			ImageFlavor:         cr.Spec.KubernetesConfig.ImageFlavor,
 		},
-		Replicas:          cr.Spec.MongoDBClusterSize,
-		Labels:            labels,
-		Annotations:       generateAnnotations(),
-		NodeSelector:      cr.Spec.KubernetesConfig.NodeSelector,
-		Affinity:          cr.Spec.KubernetesConfig.Affinity,
-		PriorityClassName: cr.Spec.KubernetesConfig.PriorityClassName,
-		Tolerations:       cr.Spec.KubernetesConfig.Tolerations,
-		SecurityContext:   cr.Spec.KubernetesConfig.SecurityContext,
+		Replicas:               cr.Spec.MongoDBClusterSize,
+		Labels:                 labels,
+		Annotations:            generateAnnotations(),
+		NodeSelector:           cr.Spec.KubernetesConfig.NodeSelector,
+		Affinity:               cr.Spec.KubernetesConfig.Affinity,
+		PriorityClassName:      cr.Spec.KubernetesConfig.PriorityClassName,
+		Tolerations:            cr.Spec.KubernetesConfig.Tolerations,
+		SecurityContext:        cr.Spec.KubernetesConfig.SecurityContext,
+		PodLabels:              mergeMaps(getMongoDBClusterMetadata(cr, podObject).Labels, cr.Spec.KubernetesConfig.PodLabels),
+		PodAnnotations:         mergeMaps(getMongoDBClusterMetadata(cr, podObject).Annotations, cr.Spec.KubernetesConfig.PodAnnotations),
+		Sidecars:               cr.Spec.KubernetesConfig.Sidecars,
+		InitContainers:         cr.Spec.KubernetesConfig.InitContainers,
+		ServiceAccountName:     cr.Spec.KubernetesConfig.ServiceAccountName,
+		TerminationGracePeriod: cr.Spec.KubernetesConfig.TerminationGracePeriodSeconds,
 	}
 
+	if IsHibernated(cr.Status.Hibernation) {
+		hibernatedReplicas := int32(0)
+		params.Replicas = &hibernatedReplicas
+	}
 	if cr.Spec.KubernetesConfig.ImagePullSecret != nil {
 		params.ImagePullSecret = cr.Spec.KubernetesConfig.ImagePullSecret
 	}
+	if cr.Spec.KubernetesConfig.ExtraVolumes != nil {
+		params.ExtraVolumes = &cr.Spec.KubernetesConfig.ExtraVolumes
+	}
+	if topologySpreadEnabled(cr) {
+		params.TopologySpreadConstraints = generateTopologySpreadConstraints(cr, labels)
+		if params.Affinity == nil {
+			params.Affinity = generatePodAntiAffinity(cr, labels)
+		}
+	}
 
 	if cr.Spec.MongoDBSecurity != nil {
 		params.ContainerParams.MongoDBUser = &cr.Spec.MongoDBSecurity.MongoDBAdminUser
-		params.ContainerParams.SecretName = cr.Spec.MongoDBSecurity.SecretRef.Name
-		params.ContainerParams.SecretKey = cr.Spec.MongoDBSecurity.SecretRef.Key
+		adminCredential := getMongoClusterAdminCredentialProvider(cr)
+		params.ContainerParams.AdminCredential = adminCredential
+		params.PodAnnotations = mergeMaps(params.PodAnnotations, adminCredential.GetPodCredential("").Annotations)
 	}
 	if cr.Spec.MongoDBMonitoring != nil {
 		params.ContainerParams.MongoDBMonitoring = &trueProperty
-		params.ContainerParams.MonitoringSecret = &monitoringSecretName
+		params.ContainerParams.MonitoringCredential = getMongoClusterMonitoringCredentialProvider(cr)
 		params.ContainerParams.MonitoringResources = cr.Spec.MongoDBMonitoring.Resources
 		params.ContainerParams.MonitoringImage = cr.Spec.MongoDBMonitoring.Image
 		params.ContainerParams.MonitoringImagePullPolicy = &cr.Spec.MongoDBMonitoring.ImagePullPolicy
+		params.ContainerParams.MonitoringLivenessProbe = cr.Spec.MongoDBMonitoring.LivenessProbe
+		params.ContainerParams.MonitoringReadinessProbe = cr.Spec.MongoDBMonitoring.ReadinessProbe
+	}
+	if operatorManagedMongod(cr.Spec.KubernetesConfig.ImageFlavor) {
+		keyfileSecret := fmt.Sprintf("%s-%s", appName, "keyfile")
+		params.ContainerParams.KeyfileSecret = &keyfileSecret
 	}
 	if cr.Spec.MongoDBAdditionalConfig != nil {
 		params.ContainerParams.AdditonalConfig = cr.Spec.MongoDBAdditionalConfig
@@ -167,12 +295,15 @@ func getMongoDBClusterParams(cr *opstreelabsinv1alpha1.MongoDBCluster) statefulS
 		params.PVCParameters = pvcParameters{
 			Name:             appName,
 			Namespace:        cr.Namespace,
-			Labels:           labels,
-			Annotations:      generateAnnotations(),
+			Labels:           mergeMaps(getMongoDBClusterMetadata(cr, pvcObject).Labels, labels),
+			Annotations:      getMongoDBClusterMetadata(cr, pvcObject).Annotations,
 			StorageSize:      cr.Spec.Storage.StorageSize,
 			StorageClassName: cr.Spec.Storage.StorageClassName,
 			AccessModes:      cr.Spec.Storage.AccessModes,
 		}
+		if bootstrapFromSnapshot(cr) {
+			params.InitContainers = append([]corev1.Container{getBootstrapInitContainer(cr)}, params.InitContainers...)
+		}
 	} else {
 		params.ContainerParams.PersistenceEnabled = &falseProperty
 	}
@@ -188,12 +319,22 @@ func getPodDisruptionParams(cr *opstreelabsinv1alpha1.MongoDBCluster) PodDisrupt
 		"role":          "cluster",
 	}
 	params := PodDisruptionParameters{
-		PDBMeta:        generateObjectMetaInformation(appName, cr.Namespace, labels, generateAnnotations()),
+		PDBMeta:        generateObjectMetaInformation(appName, cr.Namespace, labels, generateAnnotations(), getMongoDBClusterMetadata(cr, "")),
 		OwnerDef:       mongoClusterAsOwner(cr),
 		Namespace:      cr.Namespace,
 		Labels:         labels,
 		MinAvailable:   cr.Spec.PodDisruptionBudget.MinAvailable,
 		MaxUnavailable: cr.Spec.PodDisruptionBudget.MaxUnavailable,
 	}
+	if params.MinAvailable == nil && params.MaxUnavailable == nil {
+		// by default a voluntary disruption can never break the majority of the replica set
+		majority := *cr.Spec.MongoDBClusterSize/2 + 1
+		params.MinAvailable = &majority
+	}
 	return params
 }
+
+// getMongoDBClusterMetadata is a method to get the user defined labels and annotations for a type of generated object
+func getMongoDBClusterMetadata(cr *opstreelabsinv1alpha1.MongoDBCluster, object string) opstreelabsinv1alpha1.ObjectMetadata {
+	return generateUserMetadata(cr.Spec.CommonLabels, cr.Spec.CommonAnnotations, cr.Spec.MetadataOverrides, object)
+}
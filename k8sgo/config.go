@@ -0,0 +1,216 @@
+package k8sgo
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	mongodConfigKey      = "mongod.conf"
+	configHashAnnotation = "mongodb.opstreelabs.in/config-hash"
+	minCacheSizeGB       = 0.25
+	defaultCacheRatio    = 0.5
+)
+
+var setParameterKeyRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// createMongodConfig is a method to render mongod configuration in a ConfigMap and attach it to statefulset
+func createMongodConfig(config *opstreelabsinv1alpha1.MongodConfig, params *statefulSetParameters) error {
+	config, err := setWiredTigerCacheSize(config, params.ContainerParams.Resources)
+	if err != nil {
+		return err
+	}
+	if config == nil {
+		return nil
+	}
+	content, err := renderMongodConfig(config)
+	if err != nil {
+		return err
+	}
+	if params.AdditionalConfig != nil {
+		err = validateAdditionalConfig(params.Namespace, *params.AdditionalConfig)
+		if err != nil {
+			return err
+		}
+	}
+	name := fmt.Sprintf("%s-%s", params.StatefulSetMeta.Name, "config")
+	configMapParams := configMapParameters{
+		ConfigMapMeta: generateObjectMetaInformation(name, params.Namespace, params.Labels, generateAnnotations(), params.CommonMetadata),
+		OwnerDef:      params.OwnerDef,
+		Namespace:     params.Namespace,
+		Data:          map[string]string{mongodConfigKey: content},
+	}
+	err = CreateOrUpdateConfigMap(configMapParams)
+	if err != nil {
+		return err
+	}
+	params.MongodConfig = &name
+	params.ContainerParams.MongodConfig = &name
+	params.PodAnnotations = mergeMaps(params.PodAnnotations, map[string]string{
+		configHashAnnotation: fmt.Sprintf("%x", sha256.Sum256([]byte(content))),
+	})
+	return nil
+}
+
+// validateAdditionalConfig is a method to check that the user defined configuration does not collide with the generated one, both are projected in the same directory
+func validateAdditionalConfig(namespace string, name string) error {
+	configMap, err := generateK8sClient().CoreV1().ConfigMaps(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if _, ok := configMap.Data[mongodConfigKey]; ok {
+		return fmt.Errorf("ConfigMap %s cannot have a %s key when mongod is set, the file is generated by the operator", name, mongodConfigKey)
+	}
+	return nil
+}
+
+// mongodConfigGenerated is a method to check if a mongod configuration ConfigMap is generated, it is kept when the configuration is invalid
+func mongodConfigGenerated(config *opstreelabsinv1alpha1.MongodConfig, resources *corev1.ResourceRequirements) bool {
+	config, err := setWiredTigerCacheSize(config, resources)
+	return err != nil || config != nil
+}
+
+// renderMongodConfig is a method to render the mongod configuration file
+func renderMongodConfig(config *opstreelabsinv1alpha1.MongodConfig) (string, error) {
+	err := validateMongodConfig(config)
+	if err != nil {
+		return "", err
+	}
+	mongodConfig := map[string]interface{}{}
+	if config.Storage != nil {
+		storage := map[string]interface{}{}
+		if config.Storage.DirectoryPerDB != nil {
+			storage["directoryPerDB"] = *config.Storage.DirectoryPerDB
+		}
+		if config.Storage.WiredTiger != nil {
+			wiredTiger := map[string]interface{}{}
+			if config.Storage.WiredTiger.CacheSizeGB != "" {
+				cacheSizeGB, _ := strconv.ParseFloat(config.Storage.WiredTiger.CacheSizeGB, 64)
+				wiredTiger["engineConfig"] = map[string]interface{}{"cacheSizeGB": cacheSizeGB}
+			}
+			if config.Storage.WiredTiger.BlockCompressor != "" {
+				wiredTiger["collectionConfig"] = map[string]interface{}{"blockCompressor": config.Storage.WiredTiger.BlockCompressor}
+			}
+			addConfigSection(storage, "wiredTiger", wiredTiger)
+		}
+		addConfigSection(mongodConfig, "storage", storage)
+	}
+	if config.Net != nil {
+		net := map[string]interface{}{}
+		if config.Net.MaxIncomingConnections != nil {
+			net["maxIncomingConnections"] = *config.Net.MaxIncomingConnections
+		}
+		if config.Net.Compressors != "" {
+			net["compression"] = map[string]interface{}{"compressors": config.Net.Compressors}
+		}
+		addConfigSection(mongodConfig, "net", net)
+	}
+	if config.OperationProfiling != nil {
+		profiling := map[string]interface{}{}
+		if config.OperationProfiling.Mode != "" {
+			profiling["mode"] = config.OperationProfiling.Mode
+		}
+		if config.OperationProfiling.SlowOpThresholdMs != nil {
+			profiling["slowOpThresholdMs"] = *config.OperationProfiling.SlowOpThresholdMs
+		}
+		if config.OperationProfiling.SlowOpSampleRate != "" {
+			sampleRate, _ := strconv.ParseFloat(config.OperationProfiling.SlowOpSampleRate, 64)
+			profiling["slowOpSampleRate"] = sampleRate
+		}
+		addConfigSection(mongodConfig, "operationProfiling", profiling)
+	}
+	if len(config.SetParameter) > 0 {
+		setParameter := map[string]interface{}{}
+		for key, value := range config.SetParameter {
+			setParameter[key] = value
+		}
+		mongodConfig["setParameter"] = setParameter
+	}
+	content, err := yaml.Marshal(mongodConfig)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// setWiredTigerCacheSize is a method to compute the WiredTiger cache size from the container memory limit
+func setWiredTigerCacheSize(config *opstreelabsinv1alpha1.MongodConfig, resources *corev1.ResourceRequirements) (*opstreelabsinv1alpha1.MongodConfig, error) {
+	if resources == nil {
+		return config, nil
+	}
+	memoryLimit, ok := resources.Limits[corev1.ResourceMemory]
+	if !ok || memoryLimit.IsZero() {
+		return config, nil
+	}
+	ratio := defaultCacheRatio
+	if config != nil && config.Storage != nil && config.Storage.WiredTiger != nil {
+		if config.Storage.WiredTiger.CacheSizeGB != "" {
+			return config, nil
+		}
+		if config.Storage.WiredTiger.CacheSizeRatio != "" {
+			var err error
+			ratio, err = strconv.ParseFloat(config.Storage.WiredTiger.CacheSizeRatio, 64)
+			if err != nil || ratio <= 0 || ratio > 1 {
+				return nil, fmt.Errorf("invalid storage.wiredTiger.cacheSizeRatio %q, must be between 0 and 1", config.Storage.WiredTiger.CacheSizeRatio)
+			}
+		}
+	}
+	// same as the mongod default, but based on the container memory limit instead of the host memory
+	cacheSizeGB := (float64(memoryLimit.Value())/(1<<30) - 1) * ratio
+	cacheSizeGB = math.Max(math.Floor(cacheSizeGB*100)/100, minCacheSizeGB)
+
+	if config == nil {
+		config = &opstreelabsinv1alpha1.MongodConfig{}
+	} else {
+		config = config.DeepCopy()
+	}
+	if config.Storage == nil {
+		config.Storage = &opstreelabsinv1alpha1.MongodStorageConfig{}
+	}
+	if config.Storage.WiredTiger == nil {
+		config.Storage.WiredTiger = &opstreelabsinv1alpha1.MongodWiredTigerConfig{}
+	}
+	config.Storage.WiredTiger.CacheSizeGB = strconv.FormatFloat(cacheSizeGB, 'f', -1, 64)
+	return config, nil
+}
+
+// validateMongodConfig is a method to validate the mongod configuration
+func validateMongodConfig(config *opstreelabsinv1alpha1.MongodConfig) error {
+	if config.Storage != nil && config.Storage.WiredTiger != nil && config.Storage.WiredTiger.CacheSizeGB != "" {
+		cacheSizeGB, err := strconv.ParseFloat(config.Storage.WiredTiger.CacheSizeGB, 64)
+		if err != nil {
+			return fmt.Errorf("invalid storage.wiredTiger.cacheSizeGB %q: %v", config.Storage.WiredTiger.CacheSizeGB, err)
+		}
+		if cacheSizeGB < minCacheSizeGB {
+			return fmt.Errorf("storage.wiredTiger.cacheSizeGB must be at least %v", minCacheSizeGB)
+		}
+	}
+	if config.OperationProfiling != nil && config.OperationProfiling.SlowOpSampleRate != "" {
+		sampleRate, err := strconv.ParseFloat(config.OperationProfiling.SlowOpSampleRate, 64)
+		if err != nil || sampleRate < 0 || sampleRate > 1 {
+			return fmt.Errorf("invalid operationProfiling.slowOpSampleRate %q, must be between 0 and 1", config.OperationProfiling.SlowOpSampleRate)
+		}
+	}
+	for key := range config.SetParameter {
+		if !setParameterKeyRegex.MatchString(key) {
+			return fmt.Errorf("invalid setParameter key %q", key)
+		}
+	}
+	return nil
+}
+
+// addConfigSection is a method to add a non-empty section in mongod configuration
+func addConfigSection(config map[string]interface{}, name string, section map[string]interface{}) {
+	if len(section) > 0 {
+		config[name] = section
+	}
+}
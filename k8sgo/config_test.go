@@ -0,0 +1,121 @@
+package k8sgo
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+)
+
+func TestRenderMongodConfig(t *testing.T) {
+	directoryPerDB := true
+	maxConnections := int32(1000)
+	slowOpThreshold := int32(200)
+	tests := []struct {
+		name    string
+		config  *opstreelabsinv1alpha1.MongodConfig
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "empty",
+			config: &opstreelabsinv1alpha1.MongodConfig{},
+			want:   "{}\n",
+		},
+		{
+			name: "storage",
+			config: &opstreelabsinv1alpha1.MongodConfig{Storage: &opstreelabsinv1alpha1.MongodStorageConfig{
+				DirectoryPerDB: &directoryPerDB,
+				WiredTiger:     &opstreelabsinv1alpha1.MongodWiredTigerConfig{CacheSizeGB: "1.5", BlockCompressor: "zstd"},
+			}},
+			want: "storage:\n  directoryPerDB: true\n  wiredTiger:\n    collectionConfig:\n      blockCompressor: zstd\n    engineConfig:\n      cacheSizeGB: 1.5\n",
+		},
+		{
+			name:   "empty sections are omitted",
+			config: &opstreelabsinv1alpha1.MongodConfig{Storage: &opstreelabsinv1alpha1.MongodStorageConfig{WiredTiger: &opstreelabsinv1alpha1.MongodWiredTigerConfig{}}, Net: &opstreelabsinv1alpha1.MongodNetConfig{}},
+			want:   "{}\n",
+		},
+		{
+			name: "net, profiling and setParameter",
+			config: &opstreelabsinv1alpha1.MongodConfig{
+				Net:                &opstreelabsinv1alpha1.MongodNetConfig{MaxIncomingConnections: &maxConnections, Compressors: "snappy,zstd"},
+				OperationProfiling: &opstreelabsinv1alpha1.MongodOperationProfilingConfig{Mode: "slowOp", SlowOpThresholdMs: &slowOpThreshold, SlowOpSampleRate: "0.5"},
+				SetParameter:       map[string]string{"ttlMonitorEnabled": "false"},
+			},
+			want: "net:\n  compression:\n    compressors: snappy,zstd\n  maxIncomingConnections: 1000\noperationProfiling:\n  mode: slowOp\n  slowOpSampleRate: 0.5\n  slowOpThresholdMs: 200\nsetParameter:\n  ttlMonitorEnabled: \"false\"\n",
+		},
+		{
+			name:    "cache size too small",
+			config:  &opstreelabsinv1alpha1.MongodConfig{Storage: &opstreelabsinv1alpha1.MongodStorageConfig{WiredTiger: &opstreelabsinv1alpha1.MongodWiredTigerConfig{CacheSizeGB: "0.1"}}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid sample rate",
+			config:  &opstreelabsinv1alpha1.MongodConfig{OperationProfiling: &opstreelabsinv1alpha1.MongodOperationProfilingConfig{SlowOpSampleRate: "2"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid setParameter key",
+			config:  &opstreelabsinv1alpha1.MongodConfig{SetParameter: map[string]string{"bad key": "1"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderMongodConfig(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetWiredTigerCacheSize(t *testing.T) {
+	resources := func(memory string) *corev1.ResourceRequirements {
+		return &corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse(memory)}}
+	}
+	wiredTiger := func(wiredTiger opstreelabsinv1alpha1.MongodWiredTigerConfig) *opstreelabsinv1alpha1.MongodConfig {
+		return &opstreelabsinv1alpha1.MongodConfig{Storage: &opstreelabsinv1alpha1.MongodStorageConfig{WiredTiger: &wiredTiger}}
+	}
+	tests := []struct {
+		name      string
+		config    *opstreelabsinv1alpha1.MongodConfig
+		resources *corev1.ResourceRequirements
+		want      string
+		wantNil   bool
+		wantErr   bool
+	}{
+		{name: "no resources", wantNil: true},
+		{name: "no memory limit", resources: &corev1.ResourceRequirements{}, wantNil: true},
+		{name: "default ratio", resources: resources("4Gi"), want: "1.5"},
+		{name: "custom ratio", config: wiredTiger(opstreelabsinv1alpha1.MongodWiredTigerConfig{CacheSizeRatio: "0.25"}), resources: resources("5Gi"), want: "1"},
+		{name: "minimum", resources: resources("1Gi"), want: "0.25"},
+		{name: "rounded down", resources: resources("3000Mi"), want: "0.96"},
+		{name: "explicit cache size", config: wiredTiger(opstreelabsinv1alpha1.MongodWiredTigerConfig{CacheSizeGB: "2"}), resources: resources("4Gi"), want: "2"},
+		{name: "invalid ratio", config: wiredTiger(opstreelabsinv1alpha1.MongodWiredTigerConfig{CacheSizeRatio: "1.5"}), resources: resources("4Gi"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := setWiredTigerCacheSize(tt.config, tt.resources)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if tt.wantNil {
+				if got != nil {
+					t.Errorf("got %+v, want no configuration", got)
+				}
+				return
+			}
+			if got.Storage.WiredTiger.CacheSizeGB != tt.want {
+				t.Errorf("got cacheSizeGB %s, want %s", got.Storage.WiredTiger.CacheSizeGB, tt.want)
+			}
+		})
+	}
+}
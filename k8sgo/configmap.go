@@ -0,0 +1,37 @@
+package k8sgo
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// configMapParameters is an input parameter structure for ConfigMap
+type configMapParameters struct {
+	ConfigMapMeta metav1.ObjectMeta
+	OwnerDef      metav1.OwnerReference
+	Namespace     string
+	Data          map[string]string
+}
+
+// CreateOrUpdateConfigMap method will create or update MongoDB ConfigMap
+func CreateOrUpdateConfigMap(params configMapParameters) error {
+	configMapDef := generateConfigMapDef(params)
+	return serverSideApply(configMapDef, "ConfigMap", func(ctx context.Context, name string, data []byte, options metav1.PatchOptions) error {
+		_, err := generateK8sClient().CoreV1().ConfigMaps(params.Namespace).Patch(ctx, name, types.ApplyPatchType, data, options)
+		return err
+	})
+}
+
+// generateConfigMapDef is a method to generate ConfigMap definition
+func generateConfigMapDef(params configMapParameters) *corev1.ConfigMap {
+	configMap := &corev1.ConfigMap{
+		TypeMeta:   generateMetaInformation("ConfigMap", "v1"),
+		ObjectMeta: params.ConfigMapMeta,
+		Data:       params.Data,
+	}
+	AddOwnerRefToObject(configMap, params.OwnerDef)
+	return configMap
+}
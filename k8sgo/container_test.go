@@ -0,0 +1,154 @@
+package k8sgo
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestGetMongoDBReadinessProbe(t *testing.T) {
+	customProbe := &corev1.Probe{
+		Handler: corev1.Handler{
+			TCPSocket: &corev1.TCPSocketAction{},
+		},
+	}
+	tests := []struct {
+		name   string
+		params containerParameters
+		want   []string
+	}{
+		{name: "standalone", params: containerParameters{MongoSetupType: "standalone"}, want: getMongoShellCommand(mongoPingCheck)},
+		{name: "cluster", params: containerParameters{MongoSetupType: "cluster"}, want: getMongoShellCommand(mongoReplicaSetCheck)},
+		{name: "custom probe", params: containerParameters{MongoSetupType: "cluster", ReadinessProbe: customProbe}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probe := getMongoDBReadinessProbe(tt.params)
+			if tt.params.ReadinessProbe != nil {
+				if probe != tt.params.ReadinessProbe {
+					t.Errorf("got probe %v, want the custom probe", probe)
+				}
+				return
+			}
+			if !reflect.DeepEqual(probe.Handler.Exec.Command, tt.want) {
+				t.Errorf("got command %v, want %v", probe.Handler.Exec.Command, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetMongoDBProbes(t *testing.T) {
+	tests := []struct {
+		name             string
+		probe            *corev1.Probe
+		failureThreshold int32
+	}{
+		{name: "liveness probe", probe: getMongoDBProbe(), failureThreshold: 5},
+		{name: "startup probe", probe: getMongoDBStartupProbe(), failureThreshold: 60},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.probe.Handler.Exec.Command, getMongoShellCommand(mongoPingCheck)) {
+				t.Errorf("got command %v, want the ping check", tt.probe.Handler.Exec.Command)
+			}
+			if tt.probe.FailureThreshold != tt.failureThreshold {
+				t.Errorf("got failure threshold %d, want %d", tt.probe.FailureThreshold, tt.failureThreshold)
+			}
+		})
+	}
+}
+
+func TestGetMongoShellCommand(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{
+			name:   "ping check",
+			script: mongoPingCheck,
+			want:   "if command -v mongosh > /dev/null 2>&1; then mongosh --quiet --eval \"db.adminCommand('ping')\"; else mongo --quiet --eval \"db.adminCommand('ping')\"; fi",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command := getMongoShellCommand(tt.script)
+			if len(command) != 3 || command[0] != "/bin/sh" || command[1] != "-c" || command[2] != tt.want {
+				t.Errorf("got command %v, want %s", command, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetMongoDBLifecycle(t *testing.T) {
+	stepDown := "--quiet -u root -p secret --authenticationDatabase admin --eval " + mongoStepDown
+	shutdown := "--quiet -u root -p secret --authenticationDatabase admin --eval " + mongoShutdown
+	tests := []struct {
+		name         string
+		setupType    string
+		env          []string
+		passwordFile string
+		want         []string
+	}{
+		{
+			name:      "cluster with the opstree entrypoint",
+			setupType: "cluster",
+			env:       []string{"MONGO_ROOT_USERNAME=root", "MONGO_ROOT_PASSWORD=secret"},
+			want:      []string{stepDown, shutdown},
+		},
+		{
+			name:      "standalone with the official entrypoint",
+			setupType: "standalone",
+			env:       []string{"MONGO_INITDB_ROOT_USERNAME=root", "MONGO_INITDB_ROOT_PASSWORD=secret"},
+			want:      []string{shutdown},
+		},
+		{
+			name:         "cluster with the password rendered by the vault agent",
+			setupType:    "cluster",
+			env:          []string{"MONGO_INITDB_ROOT_USERNAME=root"},
+			passwordFile: "secret",
+			want:         []string{stepDown, shutdown},
+		},
+		{
+			name:      "standalone without authentication",
+			setupType: "standalone",
+			want:      []string{"--quiet --eval " + mongoShutdown},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			shell := "#!/bin/sh\necho \"$*\" >> " + filepath.Join(dir, "calls") + "\n"
+			if err := os.WriteFile(filepath.Join(dir, "mongosh"), []byte(shell), 0755); err != nil {
+				t.Fatal(err)
+			}
+			env := append([]string{"PATH=" + dir + ":" + os.Getenv("PATH")}, tt.env...)
+			if tt.passwordFile != "" {
+				passwordFile := filepath.Join(dir, "password")
+				if err := os.WriteFile(passwordFile, []byte(tt.passwordFile), 0600); err != nil {
+					t.Fatal(err)
+				}
+				env = append(env, "MONGO_INITDB_ROOT_PASSWORD_FILE="+passwordFile)
+			}
+			command := getMongoDBLifecycle(containerParameters{MongoSetupType: tt.setupType}).PreStop.Exec.Command
+			cmd := exec.Command(command[0], command[1:]...)
+			cmd.Env = env
+			if output, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("preStop hook failed: %v: %s", err, output)
+			}
+			calls, err := os.ReadFile(filepath.Join(dir, "calls"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := strings.Split(strings.TrimSuffix(string(calls), "\n"), "\n")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got calls %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
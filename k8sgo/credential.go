@@ -0,0 +1,133 @@
+package k8sgo
+
+import (
+	"context"
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+)
+
+// adminCredentialName is the name of the admin password file rendered in the pods by the Vault Agent injector
+const adminCredentialName = "mongodb-admin"
+
+// CredentialProvider is the interface to fetch a password used by the operator and to pass it to the pods, rotated passwords are picked up on the next fetch
+type CredentialProvider interface {
+	GetPassword() (string, error)
+	GetPodCredential(envName string) podCredential
+}
+
+// podCredential is how a pod reads a password, the operator never copies the password in the pod spec
+type podCredential struct {
+	// Env is the variable holding the password, or the path of the password file when its name ends with _FILE
+	Env corev1.EnvVar
+	// Annotations request the password file from the Vault Agent injector
+	Annotations map[string]string
+}
+
+// kubernetesSecretProvider fetches a password from a key of a Kubernetes secret
+type kubernetesSecretProvider struct {
+	Namespace  string
+	SecretName string
+	SecretKey  string
+}
+
+// GetPassword is a method to get the password from the Kubernetes secret
+func (p *kubernetesSecretProvider) GetPassword() (string, error) {
+	secret, err := generateK8sClient().CoreV1().Secrets(p.Namespace).Get(context.TODO(), p.SecretName, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	value, ok := secret.Data[p.SecretKey]
+	if !ok {
+		return "", fmt.Errorf("key %s not found in secret %s", p.SecretKey, p.SecretName)
+	}
+	return string(value), nil
+}
+
+// GetPodCredential is a method to pass the password to the pods as a reference to the secret key
+func (p *kubernetesSecretProvider) GetPodCredential(envName string) podCredential {
+	return podCredential{Env: corev1.EnvVar{Name: envName, ValueFrom: getSecretKeyRef(p.SecretName, p.SecretKey)}}
+}
+
+// adminPasswordInVault is a method to check if the admin password is only kept in Vault, without a Kubernetes secret
+func adminPasswordInVault(security *opstreelabsinv1alpha1.MongoDBSecurity) bool {
+	return security != nil && security.Vault != nil && !security.Vault.FallbackToSecret
+}
+
+// getAdminCredentialProvider is a method to get the provider of the admin password, Vault falls back to the secret only when it is enabled explicitly
+func getAdminCredentialProvider(namespace string, security *opstreelabsinv1alpha1.MongoDBSecurity, secretName string, secretKey string) CredentialProvider {
+	secretProvider := &kubernetesSecretProvider{Namespace: namespace, SecretName: secretName, SecretKey: secretKey}
+	if security == nil || security.Vault == nil {
+		return secretProvider
+	}
+	provider := &vaultProvider{Source: security.Vault, Name: adminCredentialName}
+	if security.Vault.FallbackToSecret {
+		provider.Fallback = secretProvider
+	}
+	return provider
+}
+
+// getMongoClusterAdminCredentialProvider is a method to get the provider of the admin password of MongoDB cluster
+func getMongoClusterAdminCredentialProvider(cr *opstreelabsinv1alpha1.MongoDBCluster) CredentialProvider {
+	secretName, secretKey := getMongoClusterAdminSecret(cr)
+	return getAdminCredentialProvider(cr.Namespace, cr.Spec.MongoDBSecurity, secretName, secretKey)
+}
+
+// getMongoStandaloneAdminCredentialProvider is a method to get the provider of the admin password of MongoDB standalone
+func getMongoStandaloneAdminCredentialProvider(cr *opstreelabsinv1alpha1.MongoDB) CredentialProvider {
+	secretName, secretKey := getMongoStandaloneAdminSecret(cr)
+	return getAdminCredentialProvider(cr.Namespace, cr.Spec.MongoDBSecurity, secretName, secretKey)
+}
+
+// getMongoClusterAdminPassword is a method to get the admin password of MongoDB cluster
+func getMongoClusterAdminPassword(cr *opstreelabsinv1alpha1.MongoDBCluster) string {
+	return getProviderPassword(cr.ObjectMeta.Name, cr.Namespace, getMongoClusterAdminCredentialProvider(cr))
+}
+
+// getMongoStandaloneAdminPassword is a method to get the admin password of MongoDB standalone
+func getMongoStandaloneAdminPassword(cr *opstreelabsinv1alpha1.MongoDB) string {
+	return getProviderPassword(cr.ObjectMeta.Name, cr.Namespace, getMongoStandaloneAdminCredentialProvider(cr))
+}
+
+// getMongoClusterMonitoringCredentialProvider is a method to get the provider of the monitoring password of MongoDB cluster, only the admin
+// password can be read from Vault, the monitoring password is generated by the operator in a secret
+func getMongoClusterMonitoringCredentialProvider(cr *opstreelabsinv1alpha1.MongoDBCluster) CredentialProvider {
+	return &kubernetesSecretProvider{Namespace: cr.Namespace, SecretName: fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster-monitoring"), SecretKey: "password"}
+}
+
+// getMongoStandaloneMonitoringCredentialProvider is a method to get the provider of the monitoring password of MongoDB standalone
+func getMongoStandaloneMonitoringCredentialProvider(cr *opstreelabsinv1alpha1.MongoDB) CredentialProvider {
+	return &kubernetesSecretProvider{Namespace: cr.Namespace, SecretName: fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "standalone-monitoring"), SecretKey: "password"}
+}
+
+// getMongoClusterBackupCredentialProvider is a method to get the provider of the backup user password of MongoDB cluster
+func getMongoClusterBackupCredentialProvider(cr *opstreelabsinv1alpha1.MongoDBCluster) CredentialProvider {
+	return &kubernetesSecretProvider{Namespace: cr.Namespace, SecretName: fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster-backup"), SecretKey: "password"}
+}
+
+// getBackupStorageCredentialProviders is a method to get the providers of the access key and secret key of the object storage
+func getBackupStorageCredentialProviders(namespace string, storage *opstreelabsinv1alpha1.BackupStorage) (CredentialProvider, CredentialProvider) {
+	accessKey := &kubernetesSecretProvider{Namespace: namespace, SecretName: storage.CredentialsSecret, SecretKey: "accessKey"}
+	secretKey := &kubernetesSecretProvider{Namespace: namespace, SecretName: storage.CredentialsSecret, SecretKey: "secretKey"}
+	return accessKey, secretKey
+}
+
+// getProviderPassword is a method to get a password from a provider, it is empty when the password cannot be fetched
+func getProviderPassword(name string, namespace string, provider CredentialProvider) string {
+	password, err := provider.GetPassword()
+	if err != nil {
+		logger := logGenerator(name, namespace, "Credential")
+		logger.Error(err, "Failed in getting the password for mongodb")
+		return ""
+	}
+	return password
+}
+
+// getJobPodAnnotations is a method to get the annotations of a job pod reading a password, the Vault Agent only renders it before the job starts
+func getJobPodAnnotations(credential podCredential) map[string]string {
+	if len(credential.Annotations) == 0 {
+		return nil
+	}
+	return mergeMaps(credential.Annotations, map[string]string{"vault.hashicorp.com/agent-pre-populate-only": "true"})
+}
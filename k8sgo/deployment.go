@@ -0,0 +1,36 @@
+package k8sgo
+
+import (
+	"context"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// CreateOrUpdateDeployment method will create or update a deployment
+func CreateOrUpdateDeployment(deployment *appsv1.Deployment) error {
+	return serverSideApply(deployment, "Deployment", func(ctx context.Context, name string, data []byte, options metav1.PatchOptions) error {
+		_, err := generateK8sClient().AppsV1().Deployments(deployment.Namespace).Patch(ctx, name, types.ApplyPatchType, data, options)
+		return err
+	})
+}
+
+// getDeployment is a method to get deployment
+func getDeployment(namespace string, deployment string) (*appsv1.Deployment, error) {
+	return generateK8sClient().AppsV1().Deployments(namespace).Get(context.TODO(), deployment, metav1.GetOptions{})
+}
+
+// deleteDeployment is a method to delete deployment, a missing deployment is not an error
+func deleteDeployment(namespace string, deployment string) error {
+	logger := logGenerator(deployment, namespace, "Deployment")
+	err := generateK8sClient().AppsV1().Deployments(namespace).Delete(context.TODO(), deployment, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		logger.Error(err, "MongoDB deployment deletion is failed")
+		return err
+	}
+	if err == nil {
+		logger.Info("MongoDB deployment deletion is successful")
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package k8sgo
+
+import (
+	"fmt"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"mongodb-operator/metrics"
+	mongogo "mongodb-operator/mongo"
+	"time"
+)
+
+// PrimarySiteFencedAnnotation is the annotation of a standby MongoDB cluster set by an external fencing system once the primary site
+// cannot accept writes anymore, automatic promotion is only allowed when it is set to "true"
+const PrimarySiteFencedAnnotation = "mongodb.opstreelabs.in/primary-site-fenced"
+
+// IsMongoClusterStandby is a method to check if MongoDB cluster is a standby which is not promoted yet
+func IsMongoClusterStandby(cr *opstreelabsinv1alpha1.MongoDBCluster) bool {
+	if cr.Spec.DisasterRecovery == nil || !cr.Spec.DisasterRecovery.Standby {
+		return false
+	}
+	return cr.Status.DisasterRecovery == nil || cr.Status.DisasterRecovery.PromotedAt == nil
+}
+
+// ReconcileMongoClusterStandby is a method to watch the primary of a standby MongoDB cluster and promote it when the primary site is lost
+func ReconcileMongoClusterStandby(cr *opstreelabsinv1alpha1.MongoDBCluster) (*opstreelabsinv1alpha1.MongoDBDisasterRecoveryStatus, error) {
+	defer metrics.StepTimer("cluster_standby")()
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Cluster Promotion")
+	status := &opstreelabsinv1alpha1.MongoDBDisasterRecoveryStatus{}
+	if cr.Status.DisasterRecovery != nil {
+		status = cr.Status.DisasterRecovery.DeepCopy()
+	}
+	mongoParams, replicaSetStatus, err := getMongoClusterMemberStatus(cr)
+	if err != nil {
+		logger.Info("Standby members are not part of the replica set yet, add them as external members of the primary cluster")
+		return status, nil
+	}
+	for _, member := range replicaSetStatus.Members {
+		if member.StateStr == "PRIMARY" {
+			status.PrimaryLostSince = nil
+			if promotionRequested(cr) {
+				logger.Info("Replica set primary is reachable, promotion is skipped to avoid a split brain", "Primary", member.Name)
+			}
+			return status, nil
+		}
+	}
+	now := metav1.Now()
+	if status.PrimaryLostSince == nil {
+		logger.Info("Standby members cannot reach the replica set primary")
+		status.PrimaryLostSince = &now
+	}
+	promotion := cr.Spec.DisasterRecovery.Promotion
+	elapsed := promotion != nil && promotion.AutomaticAfterSeconds != nil &&
+		now.Sub(status.PrimaryLostSince.Time) >= time.Duration(*promotion.AutomaticAfterSeconds)*time.Second
+	// members which cannot see a primary may be partitioned from a healthy primary site, promoting them would create a split brain
+	automatic := elapsed && primarySiteFenced(cr)
+	if elapsed && !automatic {
+		logger.Info("Automatic promotion is waiting for the primary site to be fenced", "Annotation", PrimarySiteFencedAnnotation)
+	}
+	if !promotionRequested(cr) && !automatic {
+		return status, nil
+	}
+	removed, err := mongogo.PromoteMongoClusterRS(mongoParams)
+	if err != nil {
+		logger.Error(err, "Unable to promote the standby MongoDB cluster")
+		return nil, err
+	}
+	logger.Info("Standby MongoDB cluster is promoted", "Removed", removed, "Automatic", automatic)
+	status.PrimaryLostSince = nil
+	status.PromotedAt = &now
+	status.RemovedMembers = removed
+	return status, nil
+}
+
+// promotionRequested is a method to check if the promotion of MongoDB cluster is requested manually
+func promotionRequested(cr *opstreelabsinv1alpha1.MongoDBCluster) bool {
+	return cr.Spec.DisasterRecovery.Promotion != nil && cr.Spec.DisasterRecovery.Promotion.Promote
+}
+
+// primarySiteFenced is a method to check if an external fencing system confirmed that the primary site cannot accept writes
+func primarySiteFenced(cr *opstreelabsinv1alpha1.MongoDBCluster) bool {
+	return cr.ObjectMeta.Annotations[PrimarySiteFencedAnnotation] == "true"
+}
+
+// getMongoClusterMemberStatus is a method to get the replica set status from the first reachable member of MongoDB cluster
+func getMongoClusterMemberStatus(cr *opstreelabsinv1alpha1.MongoDBCluster) (mongogo.MongoDBParameters, *mongogo.ReplicaSetStatus, error) {
+	password := getMongoClusterAdminPassword(cr)
+	memberOverrides := getMongoMemberOverrides(cr)
+	addMongoZoneTags(cr, memberOverrides)
+	mongoParams := mongogo.MongoDBParameters{
+		Namespace:       cr.Namespace,
+		Name:            cr.ObjectMeta.Name,
+		ClusterNodes:    cr.Spec.MongoDBClusterSize,
+		SetupType:       "cluster",
+		MemberOverrides: memberOverrides,
+		ExternalMembers: getMongoExternalMembers(cr),
+	}
+	var err error
+	for node := 0; node < int(*cr.Spec.MongoDBClusterSize); node++ {
+		// members are reached with the service domain, the advertised domain may only resolve from the other site
+		mongoParams.MongoURL = fmt.Sprintf("mongodb://%s:%s@%s/", cr.Spec.MongoDBSecurity.MongoDBAdminUser, password, mongogo.GetMongoNodeInfo(mongoParams, node))
+		var status *mongogo.ReplicaSetStatus
+		status, err = mongogo.GetMemberReplicaSetStatus(mongoParams)
+		if err == nil {
+			mongoParams.Domain = getMongoClusterDomain(cr)
+			return mongoParams, status, nil
+		}
+	}
+	return mongoParams, nil, err
+}
+
+// getMongoClusterDomain is a method to get the domain of the member hosts in the replica set configuration
+func getMongoClusterDomain(cr *opstreelabsinv1alpha1.MongoDBCluster) string {
+	if cr.Spec.DisasterRecovery == nil {
+		return ""
+	}
+	return cr.Spec.DisasterRecovery.AdvertisedDomain
+}
+
+// getLocalMemberOrdinal is a method to get the ordinal of the pod of a replica set member, the host must be the exact host of a local
+// node with the service or the advertised domain, so members of another site with the same pod name are not mistaken for local pods
+func getLocalMemberOrdinal(cr *opstreelabsinv1alpha1.MongoDBCluster, host string) (int, bool) {
+	params := mongogo.MongoDBParameters{Name: cr.ObjectMeta.Name, Namespace: cr.Namespace}
+	domains := []string{""}
+	if domain := getMongoClusterDomain(cr); domain != "" {
+		domains = append(domains, domain)
+	}
+	for ordinal := 0; ordinal < int(*cr.Spec.MongoDBClusterSize); ordinal++ {
+		for _, domain := range domains {
+			params.Domain = domain
+			if mongogo.GetMongoNodeInfo(params, ordinal) == host {
+				return ordinal, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// getMongoExternalMembers is a method to get the replica set members running in another Kubernetes cluster
+func getMongoExternalMembers(cr *opstreelabsinv1alpha1.MongoDBCluster) []mongogo.ExternalMember {
+	if cr.Spec.DisasterRecovery == nil {
+		return nil
+	}
+	var members []mongogo.ExternalMember
+	for _, member := range cr.Spec.DisasterRecovery.ExternalMembers {
+		members = append(members, mongogo.ExternalMember{Host: member.Host, Tags: member.Tags})
+	}
+	return members
+}
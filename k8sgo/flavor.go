@@ -0,0 +1,132 @@
+package k8sgo
+
+import (
+	"fmt"
+	"github.com/thanhpk/randstr"
+	corev1 "k8s.io/api/core/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+)
+
+const (
+	// ImageFlavorOpstree is the opstree image which configures mongod from its own entrypoint
+	ImageFlavorOpstree = "opstree"
+	// ImageFlavorOfficial is the upstream mongo image
+	ImageFlavorOfficial = "official"
+	// ImageFlavorPercona is the Percona Server for MongoDB image
+	ImageFlavorPercona = "percona"
+
+	keyfileKey        = "keyfile"
+	keyfileVolumeName = "keyfile"
+	keyfileSecretPath = "/etc/mongo.d/keyfile-secret"
+	keyfilePath       = "/etc/mongo.d/keyfile"
+)
+
+// keyfileCopyScript copies the keyfile out of the secret volume, mongod refuses keyfiles readable by the group or other users
+const keyfileCopyScript = `set -e
+cp /etc/mongo.d/keyfile-secret/keyfile /etc/mongo.d/keyfile/keyfile
+chmod 0400 /etc/mongo.d/keyfile/keyfile
+if [ "$(id -u)" = "0" ]; then
+  chown mongodb /etc/mongo.d/keyfile/keyfile
+fi
+`
+
+// operatorManagedMongod is a method to check if the operator builds the mongod command line for the image flavor
+func operatorManagedMongod(flavor string) bool {
+	return flavor != "" && flavor != ImageFlavorOpstree
+}
+
+// validateImageFlavor is a method to check that the configuration of the resource is supported by the image flavor, mongod
+// started by the operator reads a single --config file, so the additional configuration ConfigMap cannot be passed to it
+func validateImageFlavor(flavor string, additionalConfig *string) error {
+	if operatorManagedMongod(flavor) && additionalConfig != nil {
+		return fmt.Errorf("mongoDBAdditionalConfig is not supported with the %s image flavor, use the mongod configuration instead", flavor)
+	}
+	return nil
+}
+
+// getMongodArgs is a method to generate the mongod command line, it is run through the image entrypoint which creates the root user on an empty data directory
+func getMongodArgs(params containerParameters) []string {
+	args := []string{"mongod", "--bind_ip_all", fmt.Sprintf("--port=%d", mongoDBPort)}
+	if params.MongoSetupType == "cluster" && params.MongoReplicaSetName != nil {
+		args = append(args, fmt.Sprintf("--replSet=%s", *params.MongoReplicaSetName))
+	}
+	if params.KeyfileSecret != nil {
+		args = append(args, fmt.Sprintf("--keyFile=%s/%s", keyfilePath, keyfileKey))
+	} else {
+		args = append(args, "--auth")
+	}
+	if params.MongodConfig != nil {
+		args = append(args, fmt.Sprintf("--config=/etc/mongo.d/extra/%s", mongodConfigKey))
+	}
+	return args
+}
+
+// getRootUserEnvironmentVariables is a method to create the root user variables of the official and percona entrypoints, they also read the password from a _FILE variable
+func getRootUserEnvironmentVariables(params containerParameters) []corev1.EnvVar {
+	if params.AdminCredential == nil || params.MongoDBUser == nil {
+		return nil
+	}
+	return []corev1.EnvVar{
+		{Name: "MONGO_INITDB_ROOT_USERNAME", Value: *params.MongoDBUser},
+		params.AdminCredential.GetPodCredential("MONGO_INITDB_ROOT_PASSWORD").Env,
+	}
+}
+
+// getKeyfileInitContainer is a method to generate the init container preparing the replica set keyfile
+func getKeyfileInitContainer(params containerParameters) corev1.Container {
+	return corev1.Container{
+		Name:            "keyfile",
+		Image:           params.Image,
+		ImagePullPolicy: params.ImagePullPolicy,
+		Command:         []string{"/bin/sh", "-c", keyfileCopyScript},
+		SecurityContext: params.SecurityContext,
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: fmt.Sprintf("%s-secret", keyfileVolumeName), MountPath: keyfileSecretPath, ReadOnly: true},
+			{Name: keyfileVolumeName, MountPath: keyfilePath},
+		},
+	}
+}
+
+// getKeyfileVolumes is a method to generate the volumes of the replica set keyfile
+func getKeyfileVolumes(secretName string) []corev1.Volume {
+	return []corev1.Volume{
+		{
+			Name:         fmt.Sprintf("%s-secret", keyfileVolumeName),
+			VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: secretName}},
+		},
+		{
+			Name:         keyfileVolumeName,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory}},
+		},
+	}
+}
+
+// CreateMongoClusterKeyfileSecret is a method to create the internal authentication keyfile shared by the MongoDB cluster members
+func CreateMongoClusterKeyfileSecret(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "Secret")
+	appName := fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster-keyfile")
+	if CheckSecretExist(cr.Namespace, appName) {
+		return nil
+	}
+	labels := map[string]string{
+		"app":           appName,
+		"mongodb_setup": "cluster",
+		"role":          "cluster",
+	}
+	params := secretsParameters{
+		SecretsMeta: generateObjectMetaInformation(appName, cr.Namespace, labels, generateAnnotations(), getMongoDBClusterMetadata(cr, "")),
+		OwnerDef:    mongoClusterAsOwner(cr),
+		Namespace:   cr.Namespace,
+		Labels:      labels,
+		Annotations: generateAnnotations(),
+		Password:    randstr.String(756),
+		Name:        appName,
+		SecretKey:   keyfileKey,
+	}
+	err := CreateSecret(params)
+	if err != nil {
+		logger.Error(err, "Cannot create mongodb keyfile secret for cluster")
+		return err
+	}
+	return nil
+}
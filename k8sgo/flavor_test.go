@@ -0,0 +1,29 @@
+package k8sgo
+
+import (
+	"testing"
+)
+
+func TestValidateImageFlavor(t *testing.T) {
+	additionalConfig := "mongodb-extra-config"
+	tests := []struct {
+		name             string
+		flavor           string
+		additionalConfig *string
+		wantErr          bool
+	}{
+		{name: "default flavor", flavor: "", additionalConfig: &additionalConfig},
+		{name: "opstree flavor", flavor: ImageFlavorOpstree, additionalConfig: &additionalConfig},
+		{name: "official flavor", flavor: ImageFlavorOfficial},
+		{name: "official flavor with additional config", flavor: ImageFlavorOfficial, additionalConfig: &additionalConfig, wantErr: true},
+		{name: "percona flavor with additional config", flavor: ImageFlavorPercona, additionalConfig: &additionalConfig, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateImageFlavor(tt.flavor, tt.additionalConfig)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package k8sgo
+
+import (
+	"fmt"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"mongodb-operator/metrics"
+	mongogo "mongodb-operator/mongo"
+	"time"
+)
+
+const (
+	// HibernationPhaseHibernated is the phase of a MongoDB scaled down to zero
+	HibernationPhaseHibernated = "Hibernated"
+	// HibernationPhaseWaking is the phase of a MongoDB scaled up again until it is available
+	HibernationPhaseWaking = "Waking"
+	// hibernationLookback is how far the hibernation schedules are looked back, weekly, monthly and yearly schedules are covered
+	hibernationLookback = 366 * 24 * time.Hour
+)
+
+// HibernateMongoCluster is a method to hibernate or wake up MongoDB cluster, it returns the hibernation status
+func HibernateMongoCluster(cr *opstreelabsinv1alpha1.MongoDBCluster) (*opstreelabsinv1alpha1.MongoDBHibernationStatus, error) {
+	defer metrics.StepTimer("cluster_hibernation")()
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Cluster Hibernation")
+	requested, err := hibernationRequested(cr.Spec.Hibernate, cr.Spec.HibernationSchedule, time.Now())
+	if err != nil {
+		logger.Error(err, "Invalid hibernation schedule for MongoDB cluster")
+		return cr.Status.Hibernation, err
+	}
+	current := cr.Status.Hibernation
+	if !requested {
+		return wakeUp(current), nil
+	}
+	if current != nil && current.Phase == HibernationPhaseHibernated {
+		return current, nil
+	}
+	if current == nil {
+		// the writes are handed over to an up to date secondary before all the members are stopped
+		err = stepDownMongoClusterPrimary(cr, 60)
+		if err != nil {
+			logger.Info("MongoDB cluster primary step down failed, hibernating anyway", "Error", err.Error())
+		}
+	}
+	err = deleteDeployment(cr.Namespace, fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster-pitr"))
+	if err != nil {
+		return current, err
+	}
+	logger.Info("Hibernating MongoDB cluster")
+	return &opstreelabsinv1alpha1.MongoDBHibernationStatus{Phase: HibernationPhaseHibernated, LastTransitionTime: &metav1.Time{Time: time.Now()}}, nil
+}
+
+// HibernateMongoStandalone is a method to hibernate or wake up MongoDB standalone, it returns the hibernation status
+func HibernateMongoStandalone(cr *opstreelabsinv1alpha1.MongoDB) (*opstreelabsinv1alpha1.MongoDBHibernationStatus, error) {
+	defer metrics.StepTimer("standalone_hibernation")()
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Hibernation")
+	requested, err := hibernationRequested(cr.Spec.Hibernate, cr.Spec.HibernationSchedule, time.Now())
+	if err != nil {
+		logger.Error(err, "Invalid hibernation schedule for MongoDB")
+		return cr.Status.Hibernation, err
+	}
+	current := cr.Status.Hibernation
+	if !requested {
+		return wakeUp(current), nil
+	}
+	if current != nil && current.Phase == HibernationPhaseHibernated {
+		return current, nil
+	}
+	logger.Info("Hibernating MongoDB")
+	return &opstreelabsinv1alpha1.MongoDBHibernationStatus{Phase: HibernationPhaseHibernated, LastTransitionTime: &metav1.Time{Time: time.Now()}}, nil
+}
+
+// IsHibernated is a method to check if MongoDB is scaled down to zero
+func IsHibernated(status *opstreelabsinv1alpha1.MongoDBHibernationStatus) bool {
+	return status != nil && status.Phase == HibernationPhaseHibernated
+}
+
+// MongoClusterAwake is a method to check if a waking MongoDB cluster elected a primary again
+func MongoClusterAwake(status *mongogo.ReplicaSetStatus) bool {
+	return getPrimaryPodName(status) != ""
+}
+
+// wakeUp is a method to get the hibernation status of MongoDB which is not requested to hibernate anymore
+func wakeUp(current *opstreelabsinv1alpha1.MongoDBHibernationStatus) *opstreelabsinv1alpha1.MongoDBHibernationStatus {
+	if !IsHibernated(current) {
+		return current
+	}
+	return &opstreelabsinv1alpha1.MongoDBHibernationStatus{Phase: HibernationPhaseWaking, LastTransitionTime: &metav1.Time{Time: time.Now()}}
+}
+
+// hibernationRequested is a method to check if MongoDB should hibernate, the latest of the scheduled hibernate and wake actions wins
+func hibernationRequested(hibernate bool, schedule *opstreelabsinv1alpha1.MongoDBHibernationSchedule, now time.Time) (bool, error) {
+	if hibernate {
+		return true, nil
+	}
+	if schedule == nil {
+		return false, nil
+	}
+	hibernateSchedule, err := parseCronSchedule(schedule.Hibernate)
+	if err != nil {
+		return false, err
+	}
+	wakeSchedule, err := parseCronSchedule(schedule.Wake)
+	if err != nil {
+		return false, err
+	}
+	lastHibernate := hibernateSchedule.last(now, hibernationLookback)
+	lastWake := wakeSchedule.last(now, hibernationLookback)
+	return lastHibernate != nil && (lastWake == nil || lastHibernate.After(*lastWake)), nil
+}
+
+// stepDownMongoClusterPrimary is a method to step down the primary of MongoDB cluster, it cannot be elected again for stepDownSecs
+func stepDownMongoClusterPrimary(cr *opstreelabsinv1alpha1.MongoDBCluster, stepDownSecs int) error {
+	password := getMongoClusterAdminPassword(cr)
+	mongoParams := mongogo.MongoDBParameters{
+		MongoURL:  getMongoClusterURL(cr, password),
+		Namespace: cr.Namespace,
+		Name:      cr.ObjectMeta.Name,
+		SetupType: "cluster",
+	}
+	return mongogo.StepDownMongoPrimary(mongoParams, stepDownSecs, 10)
+}
@@ -0,0 +1,49 @@
+package k8sgo
+
+import (
+	"context"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CreateJobIfNotExist method will create a job and return the stored one when it already exists, jobs are never updated
+func CreateJobIfNotExist(job *batchv1.Job) (*batchv1.Job, error) {
+	logger := logGenerator(job.Name, job.Namespace, "Job")
+	storedJob, err := getJob(job.Namespace, job.Name)
+	if err == nil {
+		return storedJob, nil
+	}
+	if !errors.IsNotFound(err) {
+		return nil, err
+	}
+	createdJob, err := generateK8sClient().BatchV1().Jobs(job.Namespace).Create(context.TODO(), job, metav1.CreateOptions{})
+	if err != nil {
+		logger.Error(err, "MongoDB job creation is failed")
+		return nil, err
+	}
+	logger.Info("MongoDB job creation is successful")
+	return createdJob, nil
+}
+
+// getJob is a method to get job
+func getJob(namespace string, job string) (*batchv1.Job, error) {
+	return generateK8sClient().BatchV1().Jobs(namespace).Get(context.TODO(), job, metav1.GetOptions{})
+}
+
+// JobFinished is a method to check if a job is finished, and if it succeeded
+func JobFinished(job *batchv1.Job) (bool, bool) {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		if condition.Type == batchv1.JobComplete {
+			return true, true
+		}
+		if condition.Type == batchv1.JobFailed {
+			return true, false
+		}
+	}
+	return false, false
+}
@@ -13,8 +13,19 @@ func generateMetaInformation(resourceKind string, apiVersion string) metav1.Type
 	}
 }
 
-// generateObjectMetaInformation generates the object meta information
-func generateObjectMetaInformation(name string, namespace string, labels map[string]string, annotations map[string]string) metav1.ObjectMeta {
+const (
+	serviceObject     = "service"
+	statefulSetObject = "statefulset"
+	podObject         = "pod"
+	pvcObject         = "pvc"
+)
+
+// generateObjectMetaInformation generates the object meta information, user defined metadata never overrides the operator labels and annotations
+func generateObjectMetaInformation(name string, namespace string, labels map[string]string, annotations map[string]string, userMeta ...mongodbv1alpha1.ObjectMetadata) metav1.ObjectMeta {
+	for _, meta := range userMeta {
+		labels = mergeMaps(meta.Labels, labels)
+		annotations = mergeMaps(meta.Annotations, annotations)
+	}
 	return metav1.ObjectMeta{
 		Name:        name,
 		Namespace:   namespace,
@@ -23,6 +34,30 @@ func generateObjectMetaInformation(name string, namespace string, labels map[str
 	}
 }
 
+// generateUserMetadata generates the user defined labels and annotations for a type of generated object
+func generateUserMetadata(commonLabels, commonAnnotations map[string]string, overrides *mongodbv1alpha1.MetadataOverrides, object string) mongodbv1alpha1.ObjectMetadata {
+	meta := mongodbv1alpha1.ObjectMetadata{Labels: commonLabels, Annotations: commonAnnotations}
+	if overrides == nil {
+		return meta
+	}
+	var override *mongodbv1alpha1.ObjectMetadata
+	switch object {
+	case serviceObject:
+		override = overrides.Service
+	case statefulSetObject:
+		override = overrides.StatefulSet
+	case podObject:
+		override = overrides.Pod
+	case pvcObject:
+		override = overrides.PVC
+	}
+	if override != nil {
+		meta.Labels = mergeMaps(meta.Labels, override.Labels)
+		meta.Annotations = mergeMaps(meta.Annotations, override.Annotations)
+	}
+	return meta
+}
+
 // AddOwnerRefToObject adds the owner references to object
 func AddOwnerRefToObject(obj metav1.Object, ownerRef metav1.OwnerReference) {
 	obj.SetOwnerReferences(append(obj.GetOwnerReferences(), ownerRef))
@@ -52,11 +87,34 @@ func mongoClusterAsOwner(cr *mongodbv1alpha1.MongoDBCluster) metav1.OwnerReferen
 	}
 }
 
+// mongoRestoreAsOwner generates and returns object refernece
+func mongoRestoreAsOwner(cr *mongodbv1alpha1.MongoDBRestore) metav1.OwnerReference {
+	trueVar := true
+	return metav1.OwnerReference{
+		APIVersion: cr.APIVersion,
+		Kind:       cr.Kind,
+		Name:       cr.Name,
+		UID:        cr.UID,
+		Controller: &trueVar,
+	}
+}
+
 // LabelSelectors generates object for label selection
 func LabelSelectors(labels map[string]string) *metav1.LabelSelector {
 	return &metav1.LabelSelector{MatchLabels: labels}
 }
 
+// mergeMaps merges labels or annotations, the later maps take precedence so that selector labels are never overridden
+func mergeMaps(maps ...map[string]string) map[string]string {
+	merged := map[string]string{}
+	for _, values := range maps {
+		for key, value := range values {
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
 // generateAnnotations generates and returns annotations
 func generateAnnotations() map[string]string {
 	return map[string]string{
@@ -3,23 +3,32 @@ package k8sgo
 import (
 	"fmt"
 	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"mongodb-operator/metrics"
 	"mongodb-operator/mongo"
 	"strings"
 )
 
 // InitializeMongoDBCluster is a method to create a mongodb cluster
 func InitializeMongoDBCluster(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
+	defer metrics.StepTimer("cluster_initialize")()
 	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Cluster Setup")
-	serviceName := fmt.Sprintf("%s-%s.%s", cr.ObjectMeta.Name, "cluster", cr.Namespace)
-	passwordParams := secretsParameters{Name: cr.ObjectMeta.Name, Namespace: cr.Namespace, SecretName: *cr.Spec.MongoDBSecurity.SecretRef.Name, SecretKey: *cr.Spec.MongoDBSecurity.SecretRef.Key}
-	password := getMongoDBPassword(passwordParams)
-	mongoURL := fmt.Sprintf("mongodb://%s:%s@%s:27017/", cr.Spec.MongoDBSecurity.MongoDBAdminUser, password, serviceName)
+	password := getMongoClusterAdminPassword(cr)
+	mongoURL := fmt.Sprintf("mongodb://%s:%s@%s/", cr.Spec.MongoDBSecurity.MongoDBAdminUser, password, getMongoClusterInitHost(cr))
+	memberOverrides := getMongoMemberOverrides(cr)
+	addMongoZoneTags(cr, memberOverrides)
 	mongoParams := mongogo.MongoDBParameters{
-		MongoURL:     mongoURL,
-		Namespace:    cr.Namespace,
-		Name:         cr.ObjectMeta.Name,
-		ClusterNodes: cr.Spec.MongoDBClusterSize,
-		SetupType:    "standalone",
+		MongoURL:        mongoURL,
+		Namespace:       cr.Namespace,
+		Name:            cr.ObjectMeta.Name,
+		ClusterNodes:    cr.Spec.MongoDBClusterSize,
+		SetupType:       "standalone",
+		MemberOverrides: memberOverrides,
+		Domain:          getMongoClusterDomain(cr),
+	}
+	if getAdoptedStandalone(cr) != "" || bootstrapFromSnapshot(cr) {
+		// the other members are added one by one by the reconfiguration and sync from the adopted or restored data
+		adoptedNodes := int32(1)
+		mongoParams.ClusterNodes = &adoptedNodes
 	}
 	err := mongogo.InitiateMongoClusterRS(mongoParams)
 	if err != nil {
@@ -32,11 +41,10 @@ func InitializeMongoDBCluster(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
 
 // CheckMongoClusterStateInitialized is a method to check mongodb cluster state
 func CheckMongoClusterStateInitialized(cr *opstreelabsinv1alpha1.MongoDBCluster) (bool, error) {
+	defer metrics.StepTimer("cluster_state_check")()
 	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Cluster Setup")
-	serviceName := fmt.Sprintf("%s-%s.%s", cr.ObjectMeta.Name, "cluster", cr.Namespace)
-	passwordParams := secretsParameters{Name: cr.ObjectMeta.Name, Namespace: cr.Namespace, SecretName: *cr.Spec.MongoDBSecurity.SecretRef.Name, SecretKey: *cr.Spec.MongoDBSecurity.SecretRef.Key}
-	password := getMongoDBPassword(passwordParams)
-	mongoURL := fmt.Sprintf("mongodb://%s:%s@%s:27017/", cr.Spec.MongoDBSecurity.MongoDBAdminUser, password, serviceName)
+	password := getMongoClusterAdminPassword(cr)
+	mongoURL := fmt.Sprintf("mongodb://%s:%s@%s/", cr.Spec.MongoDBSecurity.MongoDBAdminUser, password, getMongoClusterInitHost(cr))
 	mongoParams := mongogo.MongoDBParameters{
 		MongoURL:  mongoURL,
 		Namespace: cr.Namespace,
@@ -53,12 +61,11 @@ func CheckMongoClusterStateInitialized(cr *opstreelabsinv1alpha1.MongoDBCluster)
 
 // CreateMongoDBMonitoringUser is a method to create a monitoring user for MongoDB
 func CreateMongoDBMonitoringUser(cr *opstreelabsinv1alpha1.MongoDB) error {
+	defer metrics.StepTimer("standalone_monitoring_user")()
 	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Monitoring User")
 	serviceName := fmt.Sprintf("%s-%s.%s", cr.ObjectMeta.Name, "standalone", cr.Namespace)
-	passwordParams := secretsParameters{Name: cr.ObjectMeta.Name, Namespace: cr.Namespace, SecretName: *cr.Spec.MongoDBSecurity.SecretRef.Name, SecretKey: *cr.Spec.MongoDBSecurity.SecretRef.Key}
-	password := getMongoDBPassword(passwordParams)
-	monitoringPasswordParams := secretsParameters{Name: cr.ObjectMeta.Name, Namespace: cr.Namespace, SecretName: fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "standalone-monitoring"), SecretKey: "password"}
-	monitoringPassword := getMongoDBPassword(monitoringPasswordParams)
+	password := getMongoStandaloneAdminPassword(cr)
+	monitoringPassword := getProviderPassword(cr.ObjectMeta.Name, cr.Namespace, getMongoStandaloneMonitoringCredentialProvider(cr))
 	mongoURL := fmt.Sprintf("mongodb://%s:%s@%s:27017/", cr.Spec.MongoDBSecurity.MongoDBAdminUser, password, serviceName)
 	mongoParams := mongogo.MongoDBParameters{
 		MongoURL:  mongoURL,
@@ -78,11 +85,10 @@ func CreateMongoDBMonitoringUser(cr *opstreelabsinv1alpha1.MongoDB) error {
 
 // CreateMongoDBClusterMonitoringUser is a method to create a monitoring user for MongoDB
 func CreateMongoDBClusterMonitoringUser(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
+	defer metrics.StepTimer("cluster_monitoring_user")()
 	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Monitoring User")
-	passwordParams := secretsParameters{Name: cr.ObjectMeta.Name, Namespace: cr.Namespace, SecretName: *cr.Spec.MongoDBSecurity.SecretRef.Name, SecretKey: *cr.Spec.MongoDBSecurity.SecretRef.Key}
-	password := getMongoDBPassword(passwordParams)
-	monitoringPasswordParams := secretsParameters{Name: cr.ObjectMeta.Name, Namespace: cr.Namespace, SecretName: fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster-monitoring"), SecretKey: "password"}
-	monitoringPassword := getMongoDBPassword(monitoringPasswordParams)
+	password := getMongoClusterAdminPassword(cr)
+	monitoringPassword := getProviderPassword(cr.ObjectMeta.Name, cr.Namespace, getMongoClusterMonitoringCredentialProvider(cr))
 	mongoParams := mongogo.MongoDBParameters{
 		Namespace: cr.Namespace,
 		Name:      cr.ObjectMeta.Name,
@@ -110,9 +116,9 @@ func CreateMongoDBClusterMonitoringUser(cr *opstreelabsinv1alpha1.MongoDBCluster
 
 // CheckMongoDBClusterMonitoringUser is a method to check if monitoring user exists in MongoDB
 func CheckMongoDBClusterMonitoringUser(cr *opstreelabsinv1alpha1.MongoDBCluster) bool {
+	defer metrics.StepTimer("cluster_monitoring_user_check")()
 	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Monitoring User")
-	passwordParams := secretsParameters{Name: cr.ObjectMeta.Name, Namespace: cr.Namespace, SecretName: *cr.Spec.MongoDBSecurity.SecretRef.Name, SecretKey: *cr.Spec.MongoDBSecurity.SecretRef.Key}
-	password := getMongoDBPassword(passwordParams)
+	password := getMongoClusterAdminPassword(cr)
 	monitoringUser := "monitoring"
 	mongoParams := mongogo.MongoDBParameters{
 		Namespace: cr.Namespace,
@@ -138,12 +144,57 @@ func CheckMongoDBClusterMonitoringUser(cr *opstreelabsinv1alpha1.MongoDBCluster)
 	return output
 }
 
+// DeleteMongoDBClusterMonitoringUser is a method to delete the monitoring user of MongoDB cluster when monitoring is disabled
+func DeleteMongoDBClusterMonitoringUser(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
+	defer metrics.StepTimer("cluster_monitoring_user_delete")()
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Monitoring User")
+	password := getMongoClusterAdminPassword(cr)
+	monitoringUser := "monitoring"
+	mongoParams := mongogo.MongoDBParameters{
+		MongoURL:  getMongoClusterURL(cr, password),
+		Namespace: cr.Namespace,
+		Name:      cr.ObjectMeta.Name,
+		UserName:  &monitoringUser,
+		SetupType: "cluster",
+	}
+	err := mongogo.DropMongoDBUser(mongoParams)
+	if err != nil {
+		logger.Error(err, "Unable to delete monitoring user in MongoDB cluster")
+		return err
+	}
+	logger.Info("Successfully deleted the monitoring user in MongoDB cluster")
+	return nil
+}
+
+// DeleteMongoDBMonitoringUser is a method to delete the monitoring user of MongoDB when monitoring is disabled
+func DeleteMongoDBMonitoringUser(cr *opstreelabsinv1alpha1.MongoDB) error {
+	defer metrics.StepTimer("standalone_monitoring_user_delete")()
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Monitoring User")
+	serviceName := fmt.Sprintf("%s-%s.%s", cr.ObjectMeta.Name, "standalone", cr.Namespace)
+	password := getMongoStandaloneAdminPassword(cr)
+	monitoringUser := "monitoring"
+	mongoParams := mongogo.MongoDBParameters{
+		MongoURL:  fmt.Sprintf("mongodb://%s:%s@%s:27017/", cr.Spec.MongoDBSecurity.MongoDBAdminUser, password, serviceName),
+		Namespace: cr.Namespace,
+		Name:      cr.ObjectMeta.Name,
+		UserName:  &monitoringUser,
+		SetupType: "standalone",
+	}
+	err := mongogo.DropMongoDBUser(mongoParams)
+	if err != nil {
+		logger.Error(err, "Unable to delete monitoring user in MongoDB")
+		return err
+	}
+	logger.Info("Successfully deleted the monitoring user")
+	return nil
+}
+
 // CheckMonitoringUser is a method to check if monitoring user exists in MongoDB
 func CheckMonitoringUser(cr *opstreelabsinv1alpha1.MongoDB) bool {
+	defer metrics.StepTimer("standalone_monitoring_user_check")()
 	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Monitoring User")
 	serviceName := fmt.Sprintf("%s-%s.%s", cr.ObjectMeta.Name, "standalone", cr.Namespace)
-	passwordParams := secretsParameters{Name: cr.ObjectMeta.Name, Namespace: cr.Namespace, SecretName: *cr.Spec.MongoDBSecurity.SecretRef.Name, SecretKey: *cr.Spec.MongoDBSecurity.SecretRef.Key}
-	password := getMongoDBPassword(passwordParams)
+	password := getMongoStandaloneAdminPassword(cr)
 	monitoringUser := "monitoring"
 	mongoURL := fmt.Sprintf("mongodb://%s:%s@%s:27017/", cr.Spec.MongoDBSecurity.MongoDBAdminUser, password, serviceName)
 	mongoParams := mongogo.MongoDBParameters{
@@ -160,3 +211,105 @@ func CheckMonitoringUser(cr *opstreelabsinv1alpha1.MongoDB) bool {
 	logger.Info("Successfully executed the command to check monitoring user")
 	return output
 }
+
+// ReconfigureMongoDBCluster is a method to apply the member configuration on an initialized MongoDB cluster
+func ReconfigureMongoDBCluster(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
+	defer metrics.StepTimer("cluster_reconfigure")()
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Cluster Reconfig")
+	password := getMongoClusterAdminPassword(cr)
+	memberOverrides := getMongoMemberOverrides(cr)
+	addMongoZoneTags(cr, memberOverrides)
+	mongoParams := mongogo.MongoDBParameters{
+		MongoURL:        getMongoClusterURL(cr, password),
+		Namespace:       cr.Namespace,
+		Name:            cr.ObjectMeta.Name,
+		ClusterNodes:    cr.Spec.MongoDBClusterSize,
+		SetupType:       "cluster",
+		MemberOverrides: memberOverrides,
+		ExternalMembers: getMongoExternalMembers(cr),
+		Domain:          getMongoClusterDomain(cr),
+	}
+	// voting members are changed one reconfig at a time, so the reconfigs are repeated until nothing changes
+	maxReconfigs := int(*cr.Spec.MongoDBClusterSize) + len(mongoParams.ExternalMembers) + 1
+	for reconfig := 0; reconfig < maxReconfigs; reconfig++ {
+		changed, err := mongogo.ReconfigMongoClusterRS(mongoParams)
+		if err != nil {
+			logger.Error(err, "Unable to reconfigure MongoDB cluster members")
+			return err
+		}
+		if !changed {
+			return nil
+		}
+		logger.Info("Successfully reconfigured the MongoDB cluster members")
+	}
+	return nil
+}
+
+// getMongoMemberOverrides is a method to generate member overrides for MongoDB cluster
+func getMongoMemberOverrides(cr *opstreelabsinv1alpha1.MongoDBCluster) map[int]mongogo.MemberOverride {
+	overrides := map[int]mongogo.MemberOverride{}
+	for _, member := range cr.Spec.Members {
+		if member.Ordinal >= *cr.Spec.MongoDBClusterSize {
+			continue
+		}
+		overrides[int(member.Ordinal)] = mongogo.MemberOverride{
+			Priority:           member.Priority,
+			Votes:              member.Votes,
+			Hidden:             member.Hidden,
+			SecondaryDelaySecs: member.SecondaryDelaySecs,
+			Tags:               member.Tags,
+		}
+	}
+	return overrides
+}
+
+// GetMongoClusterStatus is a method to get the replica set status of MongoDB cluster
+func GetMongoClusterStatus(cr *opstreelabsinv1alpha1.MongoDBCluster) (*mongogo.ReplicaSetStatus, error) {
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Cluster Status")
+	password := getMongoClusterAdminPassword(cr)
+	mongoParams := mongogo.MongoDBParameters{
+		MongoURL:  getMongoClusterURL(cr, password),
+		Namespace: cr.Namespace,
+		Name:      cr.ObjectMeta.Name,
+		SetupType: "cluster",
+	}
+	status, err := mongogo.GetReplicaSetStatus(mongoParams)
+	if err != nil {
+		logger.Error(err, "Unable to get replica set status of MongoDB cluster")
+		return nil, err
+	}
+	return status, nil
+}
+
+// RecordMongoClusterTopology is a method to record the replica set topology metrics of MongoDB cluster
+func RecordMongoClusterTopology(cr *opstreelabsinv1alpha1.MongoDBCluster, status *mongogo.ReplicaSetStatus) {
+	defer metrics.StepTimer("cluster_topology")()
+	var states []string
+	primary := ""
+	for _, member := range status.Members {
+		// external members are counted by the cluster of their own site
+		if _, ok := getLocalMemberOrdinal(cr, member.Name); ok {
+			states = append(states, member.StateStr)
+		}
+		if member.StateStr == "PRIMARY" {
+			primary = member.Name
+		}
+	}
+	metrics.ObserveReplicaSetMembers(cr.Namespace, cr.ObjectMeta.Name, states)
+	metrics.ObserveReplicaSetPrimary(cr.Namespace, cr.ObjectMeta.Name, primary)
+}
+
+// getMongoClusterURL is a method to generate the replica set connection URL of MongoDB cluster
+func getMongoClusterURL(cr *opstreelabsinv1alpha1.MongoDBCluster, password string) string {
+	return fmt.Sprintf("mongodb://%s:%s@%s/?replicaSet=%s", cr.Spec.MongoDBSecurity.MongoDBAdminUser, password, getMongoClusterHosts(cr), cr.ObjectMeta.Name)
+}
+
+// getMongoClusterHosts is a method to get the comma separated hosts of MongoDB cluster members
+func getMongoClusterHosts(cr *opstreelabsinv1alpha1.MongoDBCluster) string {
+	mongoParams := mongogo.MongoDBParameters{Namespace: cr.Namespace, Name: cr.ObjectMeta.Name}
+	var nodes []string
+	for node := 0; node < int(*cr.Spec.MongoDBClusterSize); node++ {
+		nodes = append(nodes, mongogo.GetMongoNodeInfo(mongoParams, node))
+	}
+	return strings.Join(nodes, ",")
+}
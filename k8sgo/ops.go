@@ -0,0 +1,223 @@
+package k8sgo
+
+import (
+	"context"
+	"fmt"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"mongodb-operator/metrics"
+	mongogo "mongodb-operator/mongo"
+)
+
+const (
+	// OpsRequestStepDown steps down the primary of MongoDB cluster
+	OpsRequestStepDown = "StepDown"
+	// OpsRequestRestart restarts a member of MongoDB cluster
+	OpsRequestRestart = "Restart"
+	// OpsRequestResync replaces the volume of a member of MongoDB cluster, which is then initial synced from the replica set
+	OpsRequestResync = "Resync"
+	// OpsRequestCompact compacts collections on a member of MongoDB cluster
+	OpsRequestCompact = "Compact"
+	// OpsRequestRotateLogs rotates the log files of MongoDB cluster members
+	OpsRequestRotateLogs = "RotateLogs"
+
+	defaultStepDownSeconds = 60
+)
+
+// invalidOperationError is returned for an operation which cannot succeed as requested, it is not retried
+type invalidOperationError struct {
+	message string
+}
+
+// Error is a method to get the message of the invalid operation
+func (e *invalidOperationError) Error() string {
+	return e.message
+}
+
+// invalidOperationf is a method to create an invalid operation error with a formatted message
+func invalidOperationf(format string, args ...interface{}) error {
+	return &invalidOperationError{message: fmt.Sprintf(format, args...)}
+}
+
+// IsRetryableError is a method to check if a failed step of an operation should be retried, Kubernetes API and MongoDB connection
+// errors are transient while invalid operations and commands refused by MongoDB fail the same way again
+func IsRetryableError(err error) bool {
+	if _, ok := err.(*invalidOperationError); ok {
+		return false
+	}
+	return !mongogo.IsCommandError(err)
+}
+
+// RunMongoClusterOpsRequest is a method to execute a step of a day-2 operation on MongoDB cluster, it returns if the operation is completed with a progress message
+func RunMongoClusterOpsRequest(ops *opstreelabsinv1alpha1.MongoDBOpsRequest, cr *opstreelabsinv1alpha1.MongoDBCluster) (bool, string, error) {
+	defer metrics.StepTimer("cluster_ops_request")()
+	logger := logGenerator(ops.ObjectMeta.Name, ops.Namespace, "MongoDB Ops Request")
+	parameters := ops.Spec.Parameters
+	if parameters == nil {
+		parameters = &opstreelabsinv1alpha1.MongoDBOpsParameters{}
+	}
+	if parameters.Member != nil && *parameters.Member >= *cr.Spec.MongoDBClusterSize {
+		return false, "", invalidOperationf("member %d is not part of MongoDB cluster %s", *parameters.Member, cr.ObjectMeta.Name)
+	}
+	if parameters.Member == nil && (ops.Spec.Type == OpsRequestRestart || ops.Spec.Type == OpsRequestResync || ops.Spec.Type == OpsRequestCompact) {
+		return false, "", invalidOperationf("%s operation requires a member", ops.Spec.Type)
+	}
+	var done bool
+	var message string
+	var err error
+	switch ops.Spec.Type {
+	case OpsRequestStepDown:
+		stepDownSeconds := defaultStepDownSeconds
+		if parameters.StepDownSeconds != nil {
+			stepDownSeconds = int(*parameters.StepDownSeconds)
+		}
+		err = stepDownMongoClusterPrimary(cr, stepDownSeconds)
+		done, message = true, "Primary stepped down"
+	case OpsRequestRestart:
+		done, message, err = restartMongoClusterMember(cr, int(*parameters.Member), ops.Status.StartTime)
+	case OpsRequestResync:
+		done, message, err = resyncMongoClusterMember(cr, int(*parameters.Member), ops.Status.StartTime)
+	case OpsRequestCompact:
+		err = compactMongoClusterMember(cr, int(*parameters.Member), parameters.Database, parameters.Collections)
+		done, message = true, fmt.Sprintf("Compacted %d collections of %s database", len(parameters.Collections), parameters.Database)
+	case OpsRequestRotateLogs:
+		err = rotateMongoClusterLogs(cr, parameters.Member)
+		done, message = true, "Rotated the log files"
+	default:
+		err = invalidOperationf("unknown operation type %s", ops.Spec.Type)
+	}
+	if err != nil {
+		logger.Error(err, "MongoDB cluster operation failed", "Type", ops.Spec.Type)
+		return false, "", err
+	}
+	logger.Info(message, "Type", ops.Spec.Type)
+	return done, message, nil
+}
+
+// restartMongoClusterMember is a method to delete the pod of a member once, and wait for its replacement to be ready
+func restartMongoClusterMember(cr *opstreelabsinv1alpha1.MongoDBCluster, member int, startTime *metav1.Time) (bool, string, error) {
+	podName := fmt.Sprintf("%s-cluster-%d", cr.ObjectMeta.Name, member)
+	pod, err := getPod(cr.Namespace, podName)
+	if errors.IsNotFound(err) {
+		return false, fmt.Sprintf("Waiting for pod %s to be recreated", podName), nil
+	}
+	if err != nil {
+		return false, "", err
+	}
+	if pod.CreationTimestamp.Before(startTime) {
+		if pod.DeletionTimestamp == nil {
+			err = deletePod(cr.Namespace, podName)
+			if err != nil {
+				return false, "", err
+			}
+		}
+		return false, fmt.Sprintf("Restarting pod %s", podName), nil
+	}
+	if !podReady(pod) {
+		return false, fmt.Sprintf("Waiting for pod %s to be ready", podName), nil
+	}
+	return true, fmt.Sprintf("Pod %s is restarted", podName), nil
+}
+
+// resyncMongoClusterMember is a method to replace the volume of a secondary member, the new member then runs an initial sync
+func resyncMongoClusterMember(cr *opstreelabsinv1alpha1.MongoDBCluster, member int, startTime *metav1.Time) (bool, string, error) {
+	podName := fmt.Sprintf("%s-cluster-%d", cr.ObjectMeta.Name, member)
+	if cr.Spec.Storage == nil {
+		// without a volume, the data is lost on restart
+		return restartMongoClusterMember(cr, member, startTime)
+	}
+	client := generateK8sClient()
+	claimName := fmt.Sprintf("%s-cluster-%s", cr.ObjectMeta.Name, podName)
+	claim, err := client.CoreV1().PersistentVolumeClaims(cr.Namespace).Get(context.TODO(), claimName, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return false, "", err
+	}
+	staleClaim := err == nil && claim.CreationTimestamp.Before(startTime)
+	if staleClaim && claim.DeletionTimestamp == nil {
+		status, err := GetMongoClusterStatus(cr)
+		if err != nil {
+			return false, "", err
+		}
+		if getPrimaryPodName(status) == podName {
+			return false, "", invalidOperationf("member %d is the primary, it must be stepped down before a resync", member)
+		}
+		// the claim is kept until its pod is deleted
+		err = client.CoreV1().PersistentVolumeClaims(cr.Namespace).Delete(context.TODO(), claimName, metav1.DeleteOptions{})
+		if err != nil {
+			return false, "", err
+		}
+	}
+	pod, err := getPod(cr.Namespace, podName)
+	if errors.IsNotFound(err) {
+		return false, fmt.Sprintf("Waiting for pod %s to be recreated", podName), nil
+	}
+	if err != nil {
+		return false, "", err
+	}
+	// a pod recreated before the stale claim is gone still uses it, so it is deleted again
+	if pod.CreationTimestamp.Before(startTime) || staleClaim {
+		if pod.DeletionTimestamp == nil {
+			err = deletePod(cr.Namespace, podName)
+			if err != nil {
+				return false, "", err
+			}
+		}
+		return false, fmt.Sprintf("Replacing the volume of pod %s", podName), nil
+	}
+	if !podReady(pod) {
+		return false, fmt.Sprintf("Waiting for pod %s to complete its initial sync", podName), nil
+	}
+	return true, fmt.Sprintf("Pod %s is resynced", podName), nil
+}
+
+// compactMongoClusterMember is a method to compact collections on a member of MongoDB cluster
+func compactMongoClusterMember(cr *opstreelabsinv1alpha1.MongoDBCluster, member int, database string, collections []string) error {
+	if database == "" || len(collections) == 0 {
+		return invalidOperationf("%s operation requires a database and collections", OpsRequestCompact)
+	}
+	mongoParams := getMongoClusterMemberParams(cr, member)
+	for _, collection := range collections {
+		err := mongogo.CompactCollection(mongoParams, database, collection)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rotateMongoClusterLogs is a method to rotate the log files of a member, or of all the members of MongoDB cluster
+func rotateMongoClusterLogs(cr *opstreelabsinv1alpha1.MongoDBCluster, member *int32) error {
+	for node := 0; node < int(*cr.Spec.MongoDBClusterSize); node++ {
+		if member != nil && int(*member) != node {
+			continue
+		}
+		err := mongogo.RotateLogs(getMongoClusterMemberParams(cr, node))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getMongoClusterMemberParams is a method to get the parameters of a direct connection to a member of MongoDB cluster
+func getMongoClusterMemberParams(cr *opstreelabsinv1alpha1.MongoDBCluster, member int) mongogo.MongoDBParameters {
+	password := getMongoClusterAdminPassword(cr)
+	mongoParams := mongogo.MongoDBParameters{
+		Namespace: cr.Namespace,
+		Name:      cr.ObjectMeta.Name,
+		SetupType: "cluster",
+	}
+	mongoParams.MongoURL = fmt.Sprintf("mongodb://%s:%s@%s/", cr.Spec.MongoDBSecurity.MongoDBAdminUser, password, mongogo.GetMongoNodeInfo(mongoParams, member))
+	return mongoParams
+}
+
+// getPrimaryPodName is a method to get the pod name of the primary member, it is empty without a primary
+func getPrimaryPodName(status *mongogo.ReplicaSetStatus) string {
+	for _, member := range status.Members {
+		if member.StateStr == "PRIMARY" {
+			return getMemberPodName(member.Name)
+		}
+	}
+	return ""
+}
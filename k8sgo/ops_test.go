@@ -0,0 +1,76 @@
+package k8sgo
+
+import (
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+)
+
+func TestIsRetryableError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "invalid operation", err: invalidOperationf("unknown operation type %s", "Repair"), want: false},
+		{name: "command refused", err: mongo.CommandError{Code: 26, Name: "NamespaceNotFound"}, want: false},
+		{name: "election", err: mongo.CommandError{Code: 11602, Name: "InterruptedDueToReplStateChange"}, want: true},
+		{name: "connection error", err: errors.New("server selection error: context deadline exceeded"), want: true},
+		{name: "api error", err: apierrors.NewServiceUnavailable("etcd is unavailable"), want: true},
+		{name: "api conflict", err: apierrors.NewConflict(schema.GroupResource{Resource: "pods"}, "mongodb-cluster-0", errors.New("modified")), want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryableError(tt.err); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunMongoClusterOpsRequestValidation(t *testing.T) {
+	size := int32(3)
+	cluster := &opstreelabsinv1alpha1.MongoDBCluster{Spec: opstreelabsinv1alpha1.MongoDBClusterSpec{MongoDBClusterSize: &size}}
+	tests := []struct {
+		name       string
+		spec       opstreelabsinv1alpha1.MongoDBOpsRequestSpec
+		wantErrMsg string
+	}{
+		{
+			name:       "member out of the cluster",
+			spec:       opstreelabsinv1alpha1.MongoDBOpsRequestSpec{Type: OpsRequestRestart, Parameters: &opstreelabsinv1alpha1.MongoDBOpsParameters{Member: int32Ptr(3)}},
+			wantErrMsg: "member 3 is not part of MongoDB cluster ",
+		},
+		{
+			name:       "missing member",
+			spec:       opstreelabsinv1alpha1.MongoDBOpsRequestSpec{Type: OpsRequestResync},
+			wantErrMsg: "Resync operation requires a member",
+		},
+		{
+			name:       "missing collections",
+			spec:       opstreelabsinv1alpha1.MongoDBOpsRequestSpec{Type: OpsRequestCompact, Parameters: &opstreelabsinv1alpha1.MongoDBOpsParameters{Member: int32Ptr(1), Database: "app"}},
+			wantErrMsg: "Compact operation requires a database and collections",
+		},
+		{
+			name:       "unknown type",
+			spec:       opstreelabsinv1alpha1.MongoDBOpsRequestSpec{Type: "Repair"},
+			wantErrMsg: "unknown operation type Repair",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops := &opstreelabsinv1alpha1.MongoDBOpsRequest{Spec: tt.spec}
+			_, _, err := RunMongoClusterOpsRequest(ops, cluster)
+			if err == nil || err.Error() != tt.wantErrMsg {
+				t.Fatalf("got error %v, want %s", err, tt.wantErrMsg)
+			}
+			if IsRetryableError(err) {
+				t.Errorf("invalid operation error %v is retryable", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package k8sgo
+
+import (
+	"context"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// getPod is a method to get pod in Kubernetes
+func getPod(namespace string, pod string) (*corev1.Pod, error) {
+	logger := logGenerator(pod, namespace, "Pod")
+	podInfo, err := generateK8sClient().CoreV1().Pods(namespace).Get(context.TODO(), pod, metav1.GetOptions{})
+	if err != nil {
+		logger.Info("MongoDB pod get action is failed")
+		return nil, err
+	}
+	return podInfo, nil
+}
+
+// getNode is a method to get node in Kubernetes
+func getNode(node string) (*corev1.Node, error) {
+	logger := logGenerator(node, "", "Node")
+	nodeInfo, err := generateK8sClient().CoreV1().Nodes().Get(context.TODO(), node, metav1.GetOptions{})
+	if err != nil {
+		logger.Info("Kubernetes node get action is failed")
+		return nil, err
+	}
+	return nodeInfo, nil
+}
+
+// deletePod is a method to delete a pod in Kubernetes, it is recreated by its StatefulSet
+func deletePod(namespace string, pod string) error {
+	logger := logGenerator(pod, namespace, "Pod")
+	err := generateK8sClient().CoreV1().Pods(namespace).Delete(context.TODO(), pod, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		logger.Error(err, "MongoDB pod deletion is failed")
+		return err
+	}
+	logger.Info("MongoDB pod deletion is successful")
+	return nil
+}
+
+// podReady is a method to check if the containers of a pod are ready
+func podReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -2,11 +2,13 @@ package k8sgo
 
 import (
 	"context"
-	"github.com/banzaicloud/k8s-objectmatcher/patch"
-	policyv1 "k8s.io/api/policy/v1beta1"
+	policyv1 "k8s.io/api/policy/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"sync"
 )
 
 // PodDisruptionParameters is an input parameter structure for Pod disruption budget
@@ -19,127 +21,121 @@ type PodDisruptionParameters struct {
 	MaxUnavailable *int32
 }
 
+var (
+	podDisruptionV1Lock sync.Mutex
+	// podDisruptionV1Supported caches the discovery of policy/v1, it is nil until the API server answered definitively
+	podDisruptionV1Supported *bool
+)
+
 // CreateOrUpdatePodDisruption method will create or update MongoDB PodDisruptionBudgets
 func CreateOrUpdatePodDisruption(params PodDisruptionParameters) error {
-	logger := logGenerator(params.PDBMeta.Name, params.Namespace, "PodDisruptionBudget")
 	pdbDef := generatePodDisruption(params)
-	storedPDB, err := getPodDisruption(params.Namespace, params.PDBMeta.Name)
-	if err != nil {
-		if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(pdbDef); err != nil {
-			logger.Error(err, "Unable to patch MongoDB PodDisruptionBudget with comparison object")
-			return err
-		}
-		if errors.IsNotFound(err) {
-			return createPodDisruption(params.Namespace, pdbDef)
-		}
-		return err
-	}
-	return patchPodDisruption(storedPDB, pdbDef, params.Namespace)
-}
-
-// patchPodDisruption will patch MongoDB Kubernetes PodDisruptionBudgets
-func patchPodDisruption(storedPdb *policyv1.PodDisruptionBudget, newPdb *policyv1.PodDisruptionBudget, namespace string) error {
-	logger := logGenerator(newPdb.Name, namespace, "PodDisruptionBudget")
-	newPdb.ResourceVersion = storedPdb.ResourceVersion
-	newPdb.CreationTimestamp = storedPdb.CreationTimestamp
-	newPdb.ManagedFields = storedPdb.ManagedFields
-
-	storedPdb.Kind = "PodDisruptionBudget"
-	storedPdb.APIVersion = "policy/v1"
-
-	patchResult, err := patch.DefaultPatchMaker.Calculate(storedPdb, newPdb,
-		patch.IgnorePDBSelector(),
-		patch.IgnoreStatusFields(),
-	)
+	supported, err := isPodDisruptionV1Supported()
 	if err != nil {
-		logger.Error(err, "Unable to patch MongoDB PodDisruption with comparison object")
 		return err
 	}
-	if !patchResult.IsEmpty() {
-		logger.Info("Changes in PodDisruptionBudget Detected, Updating...",
-			"patch", string(patchResult.Patch),
-			"Current", string(patchResult.Current),
-			"Original", string(patchResult.Original),
-			"Modified", string(patchResult.Modified),
-		)
-		for key, value := range storedPdb.Annotations {
-			if _, present := newPdb.Annotations[key]; !present {
-				newPdb.Annotations[key] = value
-			}
-		}
-		if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(newPdb); err != nil {
-			logger.Error(err, "Unable to patch MongoDB PodDisruptionBudget with comparison object")
+	if !supported {
+		return serverSideApply(convertPodDisruptionToV1beta1(pdbDef), "PodDisruptionBudget", func(ctx context.Context, name string, data []byte, options metav1.PatchOptions) error {
+			_, err := generateK8sClient().PolicyV1beta1().PodDisruptionBudgets(params.Namespace).Patch(ctx, name, types.ApplyPatchType, data, options)
 			return err
-		}
-		return updatePodDisruption(namespace, newPdb)
+		})
 	}
-	logger.Info("PodDisruptionBudget is reconciled, nothing to change")
-	return nil
+	return serverSideApply(pdbDef, "PodDisruptionBudget", func(ctx context.Context, name string, data []byte, options metav1.PatchOptions) error {
+		_, err := generateK8sClient().PolicyV1().PodDisruptionBudgets(params.Namespace).Patch(ctx, name, types.ApplyPatchType, data, options)
+		return err
+	})
 }
 
-// updatePodDisruption is a method to create Pod disruption budget
-func updatePodDisruption(namespace string, pdb *policyv1.PodDisruptionBudget) error {
-	logger := logGenerator(pdb.Name, namespace, "PodDisruptionBudget")
-	_, err := generateK8sClient().PolicyV1beta1().PodDisruptionBudgets(namespace).Update(context.TODO(), pdb, metav1.UpdateOptions{})
+// DeletePodDisruption is a method to delete MongoDB PodDisruptionBudget, a missing budget is not an error
+func DeletePodDisruption(namespace, name string) error {
+	logger := logGenerator(name, namespace, "PodDisruptionBudget")
+	supported, err := isPodDisruptionV1Supported()
 	if err != nil {
-		logger.Error(err, "MongoDB PodDisruptionBudget update failed")
 		return err
 	}
-	logger.Info("MongoDB PodDisruptionBudget update was successful", "PDB.Spec", pdb.Spec)
-	return nil
-}
-
-// createPodDisruption is a method to create Pod disruption budget
-func createPodDisruption(namespace string, pdb *policyv1.PodDisruptionBudget) error {
-	logger := logGenerator(pdb.Name, namespace, "PodDisruptionBudget")
-	_, err := generateK8sClient().PolicyV1beta1().PodDisruptionBudgets(namespace).Create(context.TODO(), pdb, metav1.CreateOptions{})
+	if supported {
+		err = generateK8sClient().PolicyV1().PodDisruptionBudgets(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+	} else {
+		err = generateK8sClient().PolicyV1beta1().PodDisruptionBudgets(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+	}
 	if err != nil {
-		logger.Error(err, "MongoDB PodDisruptionBudget creation failed")
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		logger.Error(err, "MongoDB PodDisruptionBudget deletion failed")
 		return err
 	}
-	logger.Info("MongoDB PodDisruptionBudget creation was successful", "PDB.Spec", pdb.Spec)
+	logger.Info("MongoDB PodDisruptionBudget deletion was successful")
 	return nil
 }
 
-// getPodDisruption is a method to get Pod disruption budget
-func getPodDisruption(namespace, name string) (*policyv1.PodDisruptionBudget, error) {
-	logger := logGenerator(name, namespace, "PodDisruptionBudget")
-	pdbInfo, err := generateK8sClient().PolicyV1beta1().PodDisruptionBudgets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
-	if err != nil {
-		logger.Info("Unable to get pod disruption budget")
-		return nil, err
-	}
-	logger.Info("MongoDB PodDisruptionBudget get action was successful")
-	return pdbInfo, err
-}
-
 // generatePodDisruption is a method to generate Pod disruption budget definiton
 func generatePodDisruption(params PodDisruptionParameters) *policyv1.PodDisruptionBudget {
 	pdbTemplate := &policyv1.PodDisruptionBudget{
-		TypeMeta:   generateMetaInformation("PodDisruptionBudget", "policy/v1beta1"),
+		TypeMeta:   generateMetaInformation("PodDisruptionBudget", "policy/v1"),
 		ObjectMeta: params.PDBMeta,
 		Spec: policyv1.PodDisruptionBudgetSpec{
 			Selector: &metav1.LabelSelector{
 				MatchLabels: params.Labels,
 			},
-			MaxUnavailable: &intstr.IntOrString{
-				Type:   intstr.Int,
-				IntVal: int32(*params.MaxUnavailable),
-			},
 		},
 	}
+	// a budget accepts only one of the fields, minAvailable wins when both are given
 	if params.MinAvailable != nil {
-		pdbTemplate.Spec.MinAvailable = &intstr.IntOrString{
-			Type:   intstr.Int,
-			IntVal: int32(*params.MinAvailable),
-		}
-	}
-	if params.MaxUnavailable != nil {
-		pdbTemplate.Spec.MaxUnavailable = &intstr.IntOrString{
-			Type:   intstr.Int,
-			IntVal: int32(*params.MaxUnavailable),
-		}
+		minAvailable := intstr.FromInt(int(*params.MinAvailable))
+		pdbTemplate.Spec.MinAvailable = &minAvailable
+	} else if params.MaxUnavailable != nil {
+		maxUnavailable := intstr.FromInt(int(*params.MaxUnavailable))
+		pdbTemplate.Spec.MaxUnavailable = &maxUnavailable
 	}
 	AddOwnerRefToObject(pdbTemplate, params.OwnerDef)
 	return pdbTemplate
 }
+
+// isPodDisruptionV1Supported is a method to discover if the cluster serves PodDisruptionBudgets in policy/v1, which is available from Kubernetes 1.21
+func isPodDisruptionV1Supported() (bool, error) {
+	return discoverPodDisruptionV1(generateK8sClient().Discovery().ServerResourcesForGroupVersion)
+}
+
+// discoverPodDisruptionV1 is a method to discover policy/v1 with the given discovery call. Only a definitive answer is cached, a served
+// resource list or a group version which is not found, other errors are returned so the reconcile is retried instead of falling back
+// to policy/v1beta1 for the life of the operator
+func discoverPodDisruptionV1(serverResources func(groupVersion string) (*metav1.APIResourceList, error)) (bool, error) {
+	podDisruptionV1Lock.Lock()
+	defer podDisruptionV1Lock.Unlock()
+	if podDisruptionV1Supported != nil {
+		return *podDisruptionV1Supported, nil
+	}
+	logger := logGenerator("policy/v1", "", "PodDisruptionBudget")
+	resources, err := serverResources(policyv1.SchemeGroupVersion.String())
+	if err != nil && !errors.IsNotFound(err) {
+		logger.Error(err, "Unable to discover PodDisruptionBudget policy/v1")
+		return false, err
+	}
+	supported := false
+	if err == nil {
+		for _, resource := range resources.APIResources {
+			if resource.Kind == "PodDisruptionBudget" {
+				supported = true
+			}
+		}
+	}
+	if !supported {
+		logger.Info("PodDisruptionBudget policy/v1 is not served, falling back to policy/v1beta1")
+	}
+	podDisruptionV1Supported = &supported
+	return supported, nil
+}
+
+// convertPodDisruptionToV1beta1 is a method to convert a policy/v1 PodDisruptionBudget to policy/v1beta1
+func convertPodDisruptionToV1beta1(pdb *policyv1.PodDisruptionBudget) *policyv1beta1.PodDisruptionBudget {
+	return &policyv1beta1.PodDisruptionBudget{
+		TypeMeta:   generateMetaInformation("PodDisruptionBudget", "policy/v1beta1"),
+		ObjectMeta: pdb.ObjectMeta,
+		Spec: policyv1beta1.PodDisruptionBudgetSpec{
+			Selector:       pdb.Spec.Selector,
+			MinAvailable:   pdb.Spec.MinAvailable,
+			MaxUnavailable: pdb.Spec.MaxUnavailable,
+		},
+	}
+}
@@ -0,0 +1,144 @@
+package k8sgo
+
+import (
+	"errors"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+)
+
+func TestGeneratePodDisruption(t *testing.T) {
+	one, two := int32(1), int32(2)
+	tests := []struct {
+		name               string
+		minAvailable       *int32
+		maxUnavailable     *int32
+		wantMinAvailable   *intstr.IntOrString
+		wantMaxUnavailable *intstr.IntOrString
+	}{
+		{name: "no budget"},
+		{name: "min available", minAvailable: &two, wantMinAvailable: intOrStringPtr(2)},
+		{name: "max unavailable", maxUnavailable: &one, wantMaxUnavailable: intOrStringPtr(1)},
+		{name: "min available wins", minAvailable: &two, maxUnavailable: &one, wantMinAvailable: intOrStringPtr(2)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			labels := map[string]string{"app": "mongodb-cluster"}
+			pdb := generatePodDisruption(PodDisruptionParameters{
+				PDBMeta:        metav1.ObjectMeta{Name: "mongodb-cluster", Namespace: "default"},
+				Labels:         labels,
+				Namespace:      "default",
+				MinAvailable:   tt.minAvailable,
+				MaxUnavailable: tt.maxUnavailable,
+			})
+			if !equalIntOrString(pdb.Spec.MinAvailable, tt.wantMinAvailable) || !equalIntOrString(pdb.Spec.MaxUnavailable, tt.wantMaxUnavailable) {
+				t.Errorf("got minAvailable %v, maxUnavailable %v, want minAvailable %v, maxUnavailable %v",
+					pdb.Spec.MinAvailable, pdb.Spec.MaxUnavailable, tt.wantMinAvailable, tt.wantMaxUnavailable)
+			}
+			if pdb.Spec.Selector.MatchLabels["app"] != "mongodb-cluster" {
+				t.Errorf("got selector %v", pdb.Spec.Selector)
+			}
+			beta := convertPodDisruptionToV1beta1(pdb)
+			if beta.APIVersion != "policy/v1beta1" || beta.Spec.MinAvailable != pdb.Spec.MinAvailable || beta.Spec.MaxUnavailable != pdb.Spec.MaxUnavailable {
+				t.Errorf("policy/v1beta1 budget differs: %+v", beta)
+			}
+		})
+	}
+}
+
+func TestGetPodDisruptionParams(t *testing.T) {
+	one := int32(1)
+	tests := []struct {
+		name             string
+		clusterSize      int32
+		budget           opstreelabsinv1alpha1.MongoDBPodDisruptionBudget
+		wantMinAvailable *int32
+	}{
+		{name: "three members keep a majority of two", clusterSize: 3, budget: opstreelabsinv1alpha1.MongoDBPodDisruptionBudget{Enabled: true}, wantMinAvailable: int32Ptr(2)},
+		{name: "five members keep a majority of three", clusterSize: 5, budget: opstreelabsinv1alpha1.MongoDBPodDisruptionBudget{Enabled: true}, wantMinAvailable: int32Ptr(3)},
+		{name: "max unavailable is kept", clusterSize: 3, budget: opstreelabsinv1alpha1.MongoDBPodDisruptionBudget{Enabled: true, MaxUnavailable: &one}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := &opstreelabsinv1alpha1.MongoDBCluster{ObjectMeta: metav1.ObjectMeta{Name: "mongodb", Namespace: "default"}}
+			cr.Spec.MongoDBClusterSize = &tt.clusterSize
+			cr.Spec.PodDisruptionBudget = &tt.budget
+			params := getPodDisruptionParams(cr)
+			if (params.MinAvailable == nil) != (tt.wantMinAvailable == nil) || (params.MinAvailable != nil && *params.MinAvailable != *tt.wantMinAvailable) {
+				t.Errorf("got minAvailable %v, want %v", params.MinAvailable, tt.wantMinAvailable)
+			}
+		})
+	}
+}
+
+func intOrStringPtr(value int) *intstr.IntOrString {
+	result := intstr.FromInt(value)
+	return &result
+}
+
+func int32Ptr(value int32) *int32 {
+	return &value
+}
+
+func equalIntOrString(got, want *intstr.IntOrString) bool {
+	if got == nil || want == nil {
+		return got == want
+	}
+	return *got == *want
+}
+
+func TestDiscoverPodDisruptionV1(t *testing.T) {
+	served := &metav1.APIResourceList{APIResources: []metav1.APIResource{{Name: "poddisruptionbudgets", Kind: "PodDisruptionBudget"}}}
+	notFound := apierrors.NewNotFound(schema.GroupResource{Group: "policy", Resource: "v1"}, "")
+	tests := []struct {
+		name          string
+		responses     []error
+		resources     *metav1.APIResourceList
+		want          []bool
+		wantErr       []bool
+		wantDiscovery int
+	}{
+		{name: "served", responses: []error{nil, nil}, resources: served, want: []bool{true, true}, wantErr: []bool{false, false}, wantDiscovery: 1},
+		{name: "not found", responses: []error{notFound, nil}, want: []bool{false, false}, wantErr: []bool{false, false}, wantDiscovery: 1},
+		{name: "served without budgets", responses: []error{nil, nil}, resources: &metav1.APIResourceList{}, want: []bool{false, false}, wantErr: []bool{false, false}, wantDiscovery: 1},
+		{
+			name:          "transient error is retried",
+			responses:     []error{errors.New("connection refused"), nil, nil},
+			resources:     served,
+			want:          []bool{false, true, true},
+			wantErr:       []bool{true, false, false},
+			wantDiscovery: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			podDisruptionV1Supported = nil
+			t.Cleanup(func() { podDisruptionV1Supported = nil })
+			discovery := 0
+			serverResources := func(groupVersion string) (*metav1.APIResourceList, error) {
+				err := tt.responses[discovery]
+				discovery++
+				if err != nil {
+					return nil, err
+				}
+				return tt.resources, nil
+			}
+			for i := range tt.want {
+				got, err := discoverPodDisruptionV1(serverResources)
+				if (err != nil) != tt.wantErr[i] {
+					t.Fatalf("call %d: got error %v, want error %v", i, err, tt.wantErr[i])
+				}
+				if got != tt.want[i] {
+					t.Errorf("call %d: got supported %v, want %v", i, got, tt.want[i])
+				}
+			}
+			if discovery != tt.wantDiscovery {
+				t.Errorf("got %d discovery calls, want %d", discovery, tt.wantDiscovery)
+			}
+		})
+	}
+}
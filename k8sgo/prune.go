@@ -0,0 +1,206 @@
+package k8sgo
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"mongodb-operator/metrics"
+)
+
+// ownedObjects is the set of object names generated for a MongoDB resource, indexed by kind
+type ownedObjects map[string]map[string]bool
+
+// add is a method to add generated objects of a kind in the set
+func (objects ownedObjects) add(kind string, names ...string) {
+	if objects[kind] == nil {
+		objects[kind] = map[string]bool{}
+	}
+	for _, name := range names {
+		objects[kind][name] = true
+	}
+}
+
+// ownedObject is an existing object owned by a MongoDB resource
+type ownedObject struct {
+	Kind   string
+	Meta   metav1.ObjectMeta
+	Delete func() error
+}
+
+// PruneMongoClusterObjects is a method to delete the objects owned by MongoDB cluster which are not generated anymore
+func PruneMongoClusterObjects(cr *opstreelabsinv1alpha1.MongoDBCluster, dryRun bool) error {
+	defer metrics.StepTimer("cluster_prune")()
+	appName := fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster")
+	desired := ownedObjects{}
+	desired.add("Service", appName, fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "primary"))
+	if !adminPasswordInVault(cr.Spec.MongoDBSecurity) {
+		adminSecret, _ := getMongoClusterAdminSecret(cr)
+		desired.add("Secret", adminSecret)
+	}
+	if cr.Spec.MongoDBMonitoring != nil {
+		desired.add("Service", fmt.Sprintf("%s-%s", appName, "metrics"))
+		desired.add("Secret", fmt.Sprintf("%s-%s", appName, "monitoring"))
+	}
+	if cr.Spec.Service != nil {
+		desired.add("Service", fmt.Sprintf("%s-%s", appName, "client"))
+	}
+	if cr.Spec.Backup != nil {
+		desired.add("Secret", fmt.Sprintf("%s-%s", appName, "backup"))
+	}
+	if pitrEnabled(cr) {
+		desired.add("Deployment", fmt.Sprintf("%s-%s", appName, "pitr"))
+	}
+	if cr.Spec.PodDisruptionBudget != nil && cr.Spec.PodDisruptionBudget.Enabled {
+		desired.add("PodDisruptionBudget", appName)
+	}
+	if mongodConfigGenerated(cr.Spec.MongodConfig, cr.Spec.KubernetesConfig.Resources) {
+		desired.add("ConfigMap", fmt.Sprintf("%s-%s", appName, "config"))
+	}
+	if operatorManagedMongod(cr.Spec.KubernetesConfig.ImageFlavor) {
+		desired.add("Secret", fmt.Sprintf("%s-%s", appName, "keyfile"))
+	}
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Cluster Prune")
+	return pruneOwnedObjects(cr.Namespace, cr.UID, "cluster", desired, dryRun, logger)
+}
+
+// PruneMongoStandaloneObjects is a method to delete the objects owned by MongoDB standalone which are not generated anymore
+func PruneMongoStandaloneObjects(cr *opstreelabsinv1alpha1.MongoDB, dryRun bool) error {
+	defer metrics.StepTimer("standalone_prune")()
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Standalone Prune")
+	return pruneOwnedObjects(cr.Namespace, cr.UID, "standalone", getMongoStandaloneOwnedObjects(cr), dryRun, logger)
+}
+
+// getMongoStandaloneOwnedObjects is a method to get the set of objects generated for MongoDB standalone
+func getMongoStandaloneOwnedObjects(cr *opstreelabsinv1alpha1.MongoDB) ownedObjects {
+	appName := fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "standalone")
+	desired := ownedObjects{}
+	desired.add("Service", appName)
+	if !adminPasswordInVault(cr.Spec.MongoDBSecurity) {
+		adminSecret, _ := getMongoStandaloneAdminSecret(cr)
+		desired.add("Secret", adminSecret)
+	}
+	if cr.Spec.MongoDBMonitoring != nil {
+		desired.add("Service", fmt.Sprintf("%s-%s", appName, "metrics"))
+		desired.add("Secret", fmt.Sprintf("%s-%s", appName, "monitoring"))
+	}
+	if cr.Spec.Service != nil {
+		desired.add("Service", fmt.Sprintf("%s-%s", appName, "client"))
+	}
+	if mongodConfigGenerated(cr.Spec.MongodConfig, cr.Spec.KubernetesConfig.Resources) {
+		desired.add("ConfigMap", fmt.Sprintf("%s-%s", appName, "config"))
+	}
+	return desired
+}
+
+// pruneOwnedObjects is a method to delete the labeled objects of an owner which are not in the desired set, dry run only logs them
+func pruneOwnedObjects(namespace string, owner types.UID, setup string, desired ownedObjects, dryRun bool, logger logr.Logger) error {
+	objects, err := listOwnedObjects(namespace, fmt.Sprintf("mongodb_setup=%s", setup))
+	if err != nil {
+		return err
+	}
+	for _, object := range objects {
+		if desired[object.Kind][object.Meta.Name] || !isOwnedBy(object.Meta, owner) {
+			continue
+		}
+		if dryRun {
+			logger.Info("Object is not generated anymore and would be pruned", "Kind", object.Kind, "Object", object.Meta.Name)
+			continue
+		}
+		err = object.Delete()
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		logger.Info("Object is not generated anymore and is pruned", "Kind", object.Kind, "Object", object.Meta.Name)
+	}
+	return nil
+}
+
+// listOwnedObjects is a method to list the objects of the kinds generated by the operator matching a label selector
+func listOwnedObjects(namespace string, selector string) ([]ownedObject, error) {
+	client := generateK8sClient()
+	options := metav1.ListOptions{LabelSelector: selector}
+	var objects []ownedObject
+	services, err := client.CoreV1().Services(namespace).List(context.TODO(), options)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range services.Items {
+		name := item.Name
+		objects = append(objects, ownedObject{Kind: "Service", Meta: item.ObjectMeta, Delete: func() error {
+			return client.CoreV1().Services(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		}})
+	}
+	secrets, err := client.CoreV1().Secrets(namespace).List(context.TODO(), options)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range secrets.Items {
+		name := item.Name
+		objects = append(objects, ownedObject{Kind: "Secret", Meta: item.ObjectMeta, Delete: func() error {
+			return client.CoreV1().Secrets(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		}})
+	}
+	configMaps, err := client.CoreV1().ConfigMaps(namespace).List(context.TODO(), options)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range configMaps.Items {
+		name := item.Name
+		objects = append(objects, ownedObject{Kind: "ConfigMap", Meta: item.ObjectMeta, Delete: func() error {
+			return client.CoreV1().ConfigMaps(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		}})
+	}
+	deployments, err := client.AppsV1().Deployments(namespace).List(context.TODO(), options)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range deployments.Items {
+		name := item.Name
+		objects = append(objects, ownedObject{Kind: "Deployment", Meta: item.ObjectMeta, Delete: func() error {
+			return deleteDeployment(namespace, name)
+		}})
+	}
+	var pdbs []metav1.ObjectMeta
+	supported, err := isPodDisruptionV1Supported()
+	if err != nil {
+		return nil, err
+	}
+	if supported {
+		list, err := client.PolicyV1().PodDisruptionBudgets(namespace).List(context.TODO(), options)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list.Items {
+			pdbs = append(pdbs, item.ObjectMeta)
+		}
+	} else {
+		list, err := client.PolicyV1beta1().PodDisruptionBudgets(namespace).List(context.TODO(), options)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list.Items {
+			pdbs = append(pdbs, item.ObjectMeta)
+		}
+	}
+	for _, meta := range pdbs {
+		name := meta.Name
+		objects = append(objects, ownedObject{Kind: "PodDisruptionBudget", Meta: meta, Delete: func() error {
+			return DeletePodDisruption(namespace, name)
+		}})
+	}
+	return objects, nil
+}
+
+// isOwnedBy is a method to check if an object is owned by the given owner
+func isOwnedBy(meta metav1.ObjectMeta, owner types.UID) bool {
+	for _, reference := range meta.OwnerReferences {
+		if reference.UID == owner {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package k8sgo
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+)
+
+func TestGetMongoStandaloneOwnedObjects(t *testing.T) {
+	tests := []struct {
+		name       string
+		monitoring *opstreelabsinv1alpha1.MongoDBMonitoring
+		service    *opstreelabsinv1alpha1.MongoDBService
+		want       map[string][]string
+	}{
+		{
+			name: "defaults",
+			want: map[string][]string{"Service": {"mongodb-standalone"}, "Secret": {"mongodb-standalone-admin"}},
+		},
+		{
+			name:       "monitoring",
+			monitoring: &opstreelabsinv1alpha1.MongoDBMonitoring{},
+			want:       map[string][]string{"Service": {"mongodb-standalone", "mongodb-standalone-metrics"}, "Secret": {"mongodb-standalone-admin", "mongodb-standalone-monitoring"}},
+		},
+		{
+			name:    "client service",
+			service: &opstreelabsinv1alpha1.MongoDBService{},
+			want:    map[string][]string{"Service": {"mongodb-standalone", "mongodb-standalone-client"}, "Secret": {"mongodb-standalone-admin"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := &opstreelabsinv1alpha1.MongoDB{ObjectMeta: metav1.ObjectMeta{Name: "mongodb", Namespace: "default"}}
+			cr.Spec.MongoDBSecurity = &opstreelabsinv1alpha1.MongoDBSecurity{}
+			cr.Spec.MongoDBMonitoring = tt.monitoring
+			cr.Spec.Service = tt.service
+			got := getMongoStandaloneOwnedObjects(cr)
+			for kind, names := range tt.want {
+				if len(got[kind]) != len(names) {
+					t.Errorf("got %s objects %v, want %v", kind, got[kind], names)
+				}
+				for _, name := range names {
+					if !got[kind][name] {
+						t.Errorf("%s %s is not in the owned objects %v", kind, name, got[kind])
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package k8sgo
+
+import (
+	"fmt"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"mongodb-operator/metrics"
+)
+
+const restoreVolumeName = "restore"
+
+// restoreDownloadScript downloads the latest base backup before TARGET and the oplog chunks up to TARGET. The chunks must form a
+// contiguous chain from the base backup to TARGET, chunks lost with the outbox of a recreated archiver pod would leave a gap in the
+// replayed oplog. Without TARGET, the oplog is replayed up to the end of the chain.
+const restoreDownloadScript = `set -e
+latest=""
+[ -n "$TARGET" ] || { latest=1; TARGET=$(date +%s); }
+mc alias set store "${S3_ENDPOINT}" "${S3_ACCESS_KEY}" "${S3_SECRET_KEY}" >/dev/null
+mkdir -p /restore/oplog
+base=""
+for key in $(mc ls "store/${S3_BUCKET}/${S3_PREFIX}/base/" | awk '{print $NF}'); do
+  end=${key%%.*}
+  if [ "$end" -le "$TARGET" ] && { [ -z "$base" ] || [ "$end" -gt "$base" ]; }; then
+    base=$end
+  fi
+done
+if [ -z "$base" ]; then
+  echo "No base backup found before the target time"
+  exit 1
+fi
+mc cp "store/${S3_BUCKET}/${S3_PREFIX}/base/${base}.archive.gz" /restore/base.archive.gz
+chain=""
+for key in $(mc ls "store/${S3_BUCKET}/${S3_PREFIX}/oplog/" | awk '{print $NF}' | sort -n); do
+  range=${key%%.*}
+  from=${range%-*}
+  to=${range#*-}
+  if [ "$to" -gt "$base" ] && [ "$from" -le "$TARGET" ]; then
+    if { [ -z "$chain" ] && [ "$from" -gt "$base" ]; } || { [ -n "$chain" ] && [ "$from" -ne "$chain" ]; }; then
+      echo "Archived oplog is missing from ${chain:-$base} to ${from}, the target time cannot be restored"
+      exit 1
+    fi
+    mc cp "store/${S3_BUCKET}/${S3_PREFIX}/oplog/${key}" "/restore/oplog/${key}"
+    chain=$to
+  fi
+done
+[ -n "$chain" ] || chain=$base
+if [ -n "$latest" ]; then
+  TARGET=$chain
+elif [ "$chain" -lt "$TARGET" ]; then
+  echo "Archived oplog ends at ${chain}, before the target time ${TARGET}"
+  exit 1
+fi
+echo "$TARGET" > /restore/target
+`
+
+// restoreReplayScript restores the base backup and replays the oplog chunks up to the target time, the users of the admin database are
+// kept so the credentials of the operator rotated since the backup still work
+const restoreReplayScript = `set -e
+[ -z "$MONGO_PASSWORD_FILE" ] || MONGO_PASSWORD=$(cat "$MONGO_PASSWORD_FILE")
+TARGET=$(cat /restore/target)
+URI="mongodb://${MONGO_USER}:${MONGO_PASSWORD}@${MONGO_HOSTS}/?replicaSet=${REPLICA_SET}&authSource=admin"
+mongorestore --uri="$URI" --drop --gzip --archive=/restore/base.archive.gz --oplogReplay --nsExclude='admin.system.*'
+mkdir -p /restore/empty
+for chunk in $(ls /restore/oplog | sort -n); do
+  mongorestore --uri="$URI" --gzip --oplogReplay --oplogFile=/restore/oplog/${chunk} --oplogLimit=$((TARGET + 1)):0 /restore/empty
+done
+`
+
+// CreateMongoClusterRestoreJob is a method to create the point-in-time restore job of MongoDB cluster
+func CreateMongoClusterRestoreJob(restore *opstreelabsinv1alpha1.MongoDBRestore, cr *opstreelabsinv1alpha1.MongoDBCluster) (*batchv1.Job, error) {
+	defer metrics.StepTimer("cluster_restore")()
+	logger := logGenerator(restore.ObjectMeta.Name, restore.Namespace, "Job")
+	if cr.Spec.Backup == nil || cr.Spec.Backup.Storage == nil {
+		return nil, fmt.Errorf("backup storage is not configured on MongoDB cluster %s", cr.ObjectMeta.Name)
+	}
+	job, err := CreateJobIfNotExist(generateRestoreJobDef(restore, cr))
+	if err != nil {
+		logger.Error(err, "Cannot create restore job for MongoDB cluster")
+		return nil, err
+	}
+	return job, nil
+}
+
+// generateRestoreJobDef is a method to generate the restore job of MongoDB cluster
+func generateRestoreJobDef(restore *opstreelabsinv1alpha1.MongoDBRestore, cr *opstreelabsinv1alpha1.MongoDBCluster) *batchv1.Job {
+	appName := fmt.Sprintf("%s-%s", restore.ObjectMeta.Name, "restore")
+	labels := map[string]string{
+		"app":           appName,
+		"mongodb_setup": "cluster",
+		"role":          "restore",
+	}
+	target := ""
+	if restore.Spec.TargetTime != nil {
+		target = fmt.Sprint(restore.Spec.TargetTime.Unix())
+	}
+	uploaderImage := defaultUploaderImage
+	if cr.Spec.Backup.PITR != nil && cr.Spec.Backup.PITR.UploaderImage != "" {
+		uploaderImage = cr.Spec.Backup.PITR.UploaderImage
+	}
+	image := cr.Spec.KubernetesConfig.Image
+	if cr.Spec.Backup.PITR != nil && cr.Spec.Backup.PITR.Image != "" {
+		image = cr.Spec.Backup.PITR.Image
+	}
+	volumeMounts := []corev1.VolumeMount{{Name: restoreVolumeName, MountPath: "/restore"}}
+	backoffLimit := int32(2)
+	adminCredential := getMongoClusterAdminCredentialProvider(cr).GetPodCredential("MONGO_PASSWORD")
+	job := &batchv1.Job{
+		TypeMeta:   generateMetaInformation("Job", "batch/v1"),
+		ObjectMeta: generateObjectMetaInformation(appName, restore.Namespace, labels, generateAnnotations(), getMongoDBClusterMetadata(cr, "")),
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Annotations: getJobPodAnnotations(adminCredential)},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					InitContainers: []corev1.Container{
+						{
+							Name:            "download",
+							Image:           uploaderImage,
+							ImagePullPolicy: cr.Spec.KubernetesConfig.ImagePullPolicy,
+							Command:         []string{"/bin/sh", "-c", restoreDownloadScript},
+							Env:             append(getBackupStorageEnv(cr.Namespace, cr.Spec.Backup.Storage, getBackupPrefix(cr)), corev1.EnvVar{Name: "TARGET", Value: target}),
+							VolumeMounts:    volumeMounts,
+						},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:            "restore",
+							Image:           image,
+							ImagePullPolicy: cr.Spec.KubernetesConfig.ImagePullPolicy,
+							Command:         []string{"/bin/sh", "-c", restoreReplayScript},
+							Env: []corev1.EnvVar{
+								{Name: "MONGO_HOSTS", Value: getMongoClusterHosts(cr)},
+								{Name: "REPLICA_SET", Value: cr.ObjectMeta.Name},
+								{Name: "MONGO_USER", Value: cr.Spec.MongoDBSecurity.MongoDBAdminUser},
+								adminCredential.Env,
+							},
+							VolumeMounts: volumeMounts,
+						},
+					},
+					Volumes: []corev1.Volume{
+						{Name: restoreVolumeName, VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+					},
+				},
+			},
+		},
+	}
+	if cr.Spec.KubernetesConfig.ImagePullSecret != nil {
+		job.Spec.Template.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: *cr.Spec.KubernetesConfig.ImagePullSecret}}
+	}
+	AddOwnerRefToObject(job, mongoRestoreAsOwner(restore))
+	return job
+}
@@ -0,0 +1,138 @@
+package k8sgo
+
+import (
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"mongodb-operator/metrics"
+	mongogo "mongodb-operator/mongo"
+	"strings"
+	"time"
+)
+
+const (
+	// AutoResyncPhaseRunning is the phase of a stale member whose volume is being replaced
+	AutoResyncPhaseRunning = "Running"
+	// AutoResyncPhaseCompleted is the phase of a stale member which completed its initial sync
+	AutoResyncPhaseCompleted = "Completed"
+	// AutoResyncPhaseFailed is the phase of a stale member which could not be resynced
+	AutoResyncPhaseFailed = "Failed"
+
+	defaultAutoResyncIntervalMinutes = 60
+	// staleMemberMessage is reported by members which cannot catch up from the oplog of their sync source
+	staleMemberMessage = "too stale"
+)
+
+// AutoResyncMongoCluster is a method to replace the volume of a member which is too stale to catch up, it returns the auto resync status
+func AutoResyncMongoCluster(cr *opstreelabsinv1alpha1.MongoDBCluster, recorder record.EventRecorder) (*opstreelabsinv1alpha1.MongoDBAutoResyncStatus, error) {
+	defer metrics.StepTimer("cluster_auto_resync")()
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Cluster Auto Resync")
+	current := cr.Status.AutoResync
+	if current != nil && current.Phase == AutoResyncPhaseRunning {
+		return continueAutoResync(cr, current, recorder)
+	}
+	if cr.Spec.AutoResync == nil || !cr.Spec.AutoResync.Enabled {
+		return current, nil
+	}
+	status, err := GetMongoClusterStatus(cr)
+	if err != nil {
+		// stale members are only resynced from a replica set with a primary
+		return current, nil
+	}
+	member, ordinal := getStaleMember(cr, status)
+	if member == nil {
+		return current, nil
+	}
+	interval := time.Duration(defaultAutoResyncIntervalMinutes) * time.Minute
+	if cr.Spec.AutoResync.MinIntervalMinutes != nil {
+		interval = time.Duration(*cr.Spec.AutoResync.MinIntervalMinutes) * time.Minute
+	}
+	if current != nil && current.StartTime != nil && time.Since(current.StartTime.Time) < interval {
+		recorder.Eventf(cr, corev1.EventTypeWarning, "AutoResyncRateLimited", "Member %s is too stale to catch up, the previous automatic resync started less than %s ago", member.Name, interval)
+		return current, nil
+	}
+	if !keepsMajority(status, member.Name) {
+		recorder.Eventf(cr, corev1.EventTypeWarning, "AutoResyncRefused", "Member %s is too stale to catch up, resyncing it would leave less than a majority of healthy members", member.Name)
+		return current, nil
+	}
+	logger.Info("MongoDB cluster member is too stale to catch up, replacing its volume", "Member", member.Name)
+	recorder.Eventf(cr, corev1.EventTypeNormal, "AutoResyncStarted", "Member %s is too stale to catch up, replacing its volume for an initial sync", member.Name)
+	started := &opstreelabsinv1alpha1.MongoDBAutoResyncStatus{Member: int32(ordinal), Phase: AutoResyncPhaseRunning, StartTime: &metav1.Time{Time: time.Now()}}
+	return continueAutoResync(cr, started, recorder)
+}
+
+// continueAutoResync is a method to run a step of the automatic resync of a member
+func continueAutoResync(cr *opstreelabsinv1alpha1.MongoDBCluster, current *opstreelabsinv1alpha1.MongoDBAutoResyncStatus, recorder record.EventRecorder) (*opstreelabsinv1alpha1.MongoDBAutoResyncStatus, error) {
+	podName := fmt.Sprintf("%s-cluster-%d", cr.ObjectMeta.Name, current.Member)
+	done, _, err := resyncMongoClusterMember(cr, int(current.Member), current.StartTime)
+	if err != nil && IsRetryableError(err) {
+		// the resync continues from the same step on the next reconcile
+		logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Cluster Auto Resync")
+		logger.Error(err, "Automatic resync step failed, it is retried", "Member", podName)
+		return current, err
+	}
+	if err != nil {
+		recorder.Eventf(cr, corev1.EventTypeWarning, "AutoResyncFailed", "Automatic resync of member %s failed: %v", podName, err)
+		failed := *current
+		failed.Phase = AutoResyncPhaseFailed
+		failed.CompletionTime = &metav1.Time{Time: time.Now()}
+		return &failed, err
+	}
+	if !done {
+		return current, nil
+	}
+	recorder.Eventf(cr, corev1.EventTypeNormal, "AutoResyncCompleted", "Member %s completed its initial sync", podName)
+	completed := *current
+	completed.Phase = AutoResyncPhaseCompleted
+	completed.CompletionTime = &metav1.Time{Time: time.Now()}
+	return &completed, nil
+}
+
+// getStaleMember is a method to get a member of the cluster stuck in RECOVERING because it fell off the oplog, with its ordinal
+func getStaleMember(cr *opstreelabsinv1alpha1.MongoDBCluster, status *mongogo.ReplicaSetStatus) (*mongogo.ReplicaSetMember, int) {
+	for i, member := range status.Members {
+		if member.StateStr != "RECOVERING" {
+			continue
+		}
+		// only the volumes of local members are replaced, external members are resynced by their own site
+		ordinal, ok := getLocalMemberOrdinal(cr, member.Name)
+		if !ok {
+			continue
+		}
+		if strings.Contains(member.LastHeartbeatMessage, staleMemberMessage) || strings.Contains(member.InfoMessage, staleMemberMessage) {
+			return &status.Members[i], ordinal
+		}
+		// the primary may not relay the message, the member reports it itself
+		memberStatus, err := mongogo.GetMemberReplicaSetStatus(getMongoClusterMemberParams(cr, ordinal))
+		if err != nil {
+			continue
+		}
+		for _, self := range memberStatus.Members {
+			if self.Self && strings.Contains(self.InfoMessage, staleMemberMessage) {
+				return &status.Members[i], ordinal
+			}
+		}
+	}
+	return nil, 0
+}
+
+// keepsMajority is a method to check if a majority of the voting members is still healthy without the given member
+func keepsMajority(status *mongogo.ReplicaSetStatus, name string) bool {
+	voting := 0
+	healthy := 0
+	for _, member := range status.Members {
+		if member.Votes == 0 {
+			continue
+		}
+		voting++
+		if member.Name == name || member.Health != 1 {
+			continue
+		}
+		if member.StateStr == "PRIMARY" || member.StateStr == "SECONDARY" || member.StateStr == "ARBITER" {
+			healthy++
+		}
+	}
+	return healthy >= voting/2+1
+}
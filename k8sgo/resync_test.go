@@ -0,0 +1,139 @@
+package k8sgo
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	mongogo "mongodb-operator/mongo"
+)
+
+func TestKeepsMajority(t *testing.T) {
+	member := func(name string, state string, votes int) mongogo.ReplicaSetMember {
+		health := float64(1)
+		if state == "(not reachable/healthy)" {
+			health = 0
+		}
+		return mongogo.ReplicaSetMember{Name: name, StateStr: state, Health: health, Votes: votes}
+	}
+	tests := []struct {
+		name    string
+		members []mongogo.ReplicaSetMember
+		want    bool
+	}{
+		{
+			name:    "healthy majority",
+			members: []mongogo.ReplicaSetMember{member("m0", "PRIMARY", 1), member("m1", "SECONDARY", 1), member("m2", "RECOVERING", 1)},
+			want:    true,
+		},
+		{
+			name:    "no majority left",
+			members: []mongogo.ReplicaSetMember{member("m0", "PRIMARY", 1), member("m1", "(not reachable/healthy)", 1), member("m2", "RECOVERING", 1)},
+			want:    false,
+		},
+		{
+			name: "non-voting members are not a majority",
+			members: []mongogo.ReplicaSetMember{
+				member("m0", "PRIMARY", 1), member("m1", "(not reachable/healthy)", 1), member("m2", "RECOVERING", 1),
+				member("m3", "SECONDARY", 0), member("m4", "SECONDARY", 0),
+			},
+			want: false,
+		},
+		{
+			name: "non-voting members do not raise the majority",
+			members: []mongogo.ReplicaSetMember{
+				member("m0", "PRIMARY", 1), member("m1", "SECONDARY", 1), member("m2", "RECOVERING", 1),
+				member("m3", "(not reachable/healthy)", 0), member("m4", "(not reachable/healthy)", 0),
+			},
+			want: true,
+		},
+		{
+			name:    "stale non-voting member",
+			members: []mongogo.ReplicaSetMember{member("m0", "PRIMARY", 1), member("m1", "SECONDARY", 1), member("m2", "RECOVERING", 0)},
+			want:    true,
+		},
+		{
+			name:    "arbiter votes",
+			members: []mongogo.ReplicaSetMember{member("m0", "PRIMARY", 1), member("m1", "ARBITER", 1), member("m2", "RECOVERING", 1)},
+			want:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := &mongogo.ReplicaSetStatus{Members: tt.members}
+			if got := keepsMajority(status, "m2"); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetStaleMember(t *testing.T) {
+	size := int32(3)
+	cr := &opstreelabsinv1alpha1.MongoDBCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "mongodb", Namespace: "default"},
+		Spec: opstreelabsinv1alpha1.MongoDBClusterSpec{
+			MongoDBClusterSize: &size,
+			DisasterRecovery:   &opstreelabsinv1alpha1.MongoDBDisasterRecovery{AdvertisedDomain: "primary.example.com"},
+		},
+	}
+	host := func(pod string) string {
+		return pod + ".mongodb-cluster.default:27017"
+	}
+	stale := "our last optime fetched is too stale to catch up"
+	tests := []struct {
+		name        string
+		members     []mongogo.ReplicaSetMember
+		wantMember  string
+		wantOrdinal int
+	}{
+		{
+			name:    "no stale member",
+			members: []mongogo.ReplicaSetMember{{Name: host("mongodb-cluster-0"), StateStr: "PRIMARY"}, {Name: host("mongodb-cluster-1"), StateStr: "SECONDARY", LastHeartbeatMessage: stale}},
+		},
+		{
+			name:        "stale member reported by the primary",
+			members:     []mongogo.ReplicaSetMember{{Name: host("mongodb-cluster-0"), StateStr: "PRIMARY"}, {Name: host("mongodb-cluster-2"), StateStr: "RECOVERING", LastHeartbeatMessage: stale}},
+			wantMember:  host("mongodb-cluster-2"),
+			wantOrdinal: 2,
+		},
+		{
+			name:        "stale member reported in its info message",
+			members:     []mongogo.ReplicaSetMember{{Name: host("mongodb-cluster-1"), StateStr: "RECOVERING", InfoMessage: stale}},
+			wantMember:  host("mongodb-cluster-1"),
+			wantOrdinal: 1,
+		},
+		{
+			name:    "member of another cluster",
+			members: []mongogo.ReplicaSetMember{{Name: host("other-cluster-1"), StateStr: "RECOVERING", LastHeartbeatMessage: stale}},
+		},
+		{
+			name:        "stale member with the advertised domain",
+			members:     []mongogo.ReplicaSetMember{{Name: "mongodb-cluster-1.primary.example.com:27017", StateStr: "RECOVERING", LastHeartbeatMessage: stale}},
+			wantMember:  "mongodb-cluster-1.primary.example.com:27017",
+			wantOrdinal: 1,
+		},
+		{
+			name:    "stale external member with the name of a local pod",
+			members: []mongogo.ReplicaSetMember{{Name: host("mongodb-cluster-0"), StateStr: "PRIMARY"}, {Name: "mongodb-cluster-1.dr.example.com:27017", StateStr: "RECOVERING", LastHeartbeatMessage: stale}},
+		},
+		{
+			name:    "member beyond the cluster size",
+			members: []mongogo.ReplicaSetMember{{Name: host("mongodb-cluster-3"), StateStr: "RECOVERING", LastHeartbeatMessage: stale}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			member, ordinal := getStaleMember(cr, &mongogo.ReplicaSetStatus{Members: tt.members})
+			if tt.wantMember == "" {
+				if member != nil {
+					t.Errorf("got stale member %s, want none", member.Name)
+				}
+				return
+			}
+			if member == nil || member.Name != tt.wantMember || ordinal != tt.wantOrdinal {
+				t.Errorf("got stale member %v with ordinal %d, want %s with ordinal %d", member, ordinal, tt.wantMember, tt.wantOrdinal)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package k8sgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"mongodb-operator/metrics"
+	mongogo "mongodb-operator/mongo"
+	"strings"
+)
+
+const (
+	roleLabel     = "mongodb.opstreelabs.in/role"
+	primaryRole   = "primary"
+	secondaryRole = "secondary"
+	arbiterRole   = "arbiter"
+	hiddenRole    = "hidden"
+)
+
+// memberRole is the replica set role of a local MongoDB pod
+type memberRole struct {
+	PodName string
+	Role    string
+}
+
+// UpdateMongoClusterRoleLabels is a method to label the MongoDB cluster pods with their replica set role
+func UpdateMongoClusterRoleLabels(cr *opstreelabsinv1alpha1.MongoDBCluster, status *mongogo.ReplicaSetStatus) error {
+	defer metrics.StepTimer("cluster_role_labels")()
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Cluster Roles")
+	roles := getMongoClusterMemberRoles(cr, status)
+	if len(roles) == 0 || roles[len(roles)-1].Role != primaryRole {
+		logger.Info("MongoDB cluster has no local primary, an election may be in progress")
+	}
+	for _, role := range roles {
+		err := setPodRoleLabel(cr.Namespace, role.PodName, role.Role)
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// getMongoClusterMemberRoles is a method to get the roles of the local pods, the primary comes last so the old primary is relabeled
+// first and the primary service never selects two pods after an election. External members are skipped, a member of another site
+// may have the name of a local pod
+func getMongoClusterMemberRoles(cr *opstreelabsinv1alpha1.MongoDBCluster, status *mongogo.ReplicaSetStatus) []memberRole {
+	var roles []memberRole
+	var primary *memberRole
+	for _, member := range status.Members {
+		ordinal, ok := getLocalMemberOrdinal(cr, member.Name)
+		if !ok {
+			continue
+		}
+		role := memberRole{PodName: fmt.Sprintf("%s-cluster-%d", cr.ObjectMeta.Name, ordinal), Role: getMemberRole(member)}
+		if role.Role == primaryRole {
+			primary = &role
+			continue
+		}
+		roles = append(roles, role)
+	}
+	if primary != nil {
+		roles = append(roles, *primary)
+	}
+	return roles
+}
+
+// getMemberRole is a method to get the role of a replica set member, members which cannot serve traffic have no role
+func getMemberRole(member mongogo.ReplicaSetMember) string {
+	switch member.StateStr {
+	case "PRIMARY":
+		return primaryRole
+	case "ARBITER":
+		return arbiterRole
+	case "SECONDARY":
+		if member.Hidden {
+			return hiddenRole
+		}
+		return secondaryRole
+	}
+	return ""
+}
+
+// getMemberPodName is a method to get the pod name from the replica set member host
+func getMemberPodName(host string) string {
+	return strings.SplitN(host, ".", 2)[0]
+}
+
+// setPodRoleLabel is a method to patch the role label of a MongoDB pod, an empty role removes the label
+func setPodRoleLabel(namespace string, podName string, role string) error {
+	logger := logGenerator(podName, namespace, "Pod")
+	pod, err := getPod(namespace, podName)
+	if err != nil {
+		return err
+	}
+	if pod.Labels[roleLabel] == role {
+		return nil
+	}
+	var value interface{} = role
+	if role == "" {
+		value = nil
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": map[string]interface{}{roleLabel: value},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = generateK8sClient().CoreV1().Pods(namespace).Patch(context.TODO(), podName, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		logger.Error(err, "Unable to update the role label of MongoDB pod")
+		return err
+	}
+	if role == primaryRole {
+		logger.Info("MongoDB pod is elected as primary of the replica set")
+	}
+	logger.Info("MongoDB pod role label is updated", "Role", role)
+	return nil
+}
@@ -0,0 +1,102 @@
+package k8sgo
+
+import (
+	"fmt"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	mongogo "mongodb-operator/mongo"
+)
+
+func TestGetMemberRole(t *testing.T) {
+	tests := []struct {
+		name   string
+		member mongogo.ReplicaSetMember
+		want   string
+	}{
+		{name: "primary", member: mongogo.ReplicaSetMember{StateStr: "PRIMARY"}, want: primaryRole},
+		{name: "secondary", member: mongogo.ReplicaSetMember{StateStr: "SECONDARY"}, want: secondaryRole},
+		{name: "hidden", member: mongogo.ReplicaSetMember{StateStr: "SECONDARY", Hidden: true}, want: hiddenRole},
+		{name: "arbiter", member: mongogo.ReplicaSetMember{StateStr: "ARBITER"}, want: arbiterRole},
+		{name: "initial sync", member: mongogo.ReplicaSetMember{StateStr: "STARTUP2"}, want: ""},
+		{name: "down", member: mongogo.ReplicaSetMember{StateStr: "(not reachable/healthy)"}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getMemberRole(tt.member); got != tt.want {
+				t.Errorf("got role %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetMemberPodName(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{host: "mongodb-cluster-0.mongodb-cluster.default.svc.cluster.local:27017", want: "mongodb-cluster-0"},
+		{host: "mongodb-cluster-1", want: "mongodb-cluster-1"},
+	}
+	for _, tt := range tests {
+		if got := getMemberPodName(tt.host); got != tt.want {
+			t.Errorf("%s: got pod %q, want %q", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestGetMongoClusterMemberRoles(t *testing.T) {
+	size := int32(3)
+	cr := &opstreelabsinv1alpha1.MongoDBCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "mongodb", Namespace: "default"},
+		Spec: opstreelabsinv1alpha1.MongoDBClusterSpec{
+			MongoDBClusterSize: &size,
+			DisasterRecovery:   &opstreelabsinv1alpha1.MongoDBDisasterRecovery{AdvertisedDomain: "primary.example.com"},
+		},
+	}
+	local := func(ordinal int, state string) mongogo.ReplicaSetMember {
+		return mongogo.ReplicaSetMember{Name: fmt.Sprintf("mongodb-cluster-%d.primary.example.com:27017", ordinal), StateStr: state}
+	}
+	external := func(ordinal int, state string) mongogo.ReplicaSetMember {
+		return mongogo.ReplicaSetMember{Name: fmt.Sprintf("mongodb-cluster-%d.dr.example.com:27017", ordinal), StateStr: state}
+	}
+	tests := []struct {
+		name    string
+		members []mongogo.ReplicaSetMember
+		want    []memberRole
+	}{
+		{
+			name:    "primary comes last",
+			members: []mongogo.ReplicaSetMember{local(0, "PRIMARY"), local(1, "SECONDARY"), local(2, "STARTUP2")},
+			want:    []memberRole{{PodName: "mongodb-cluster-1", Role: secondaryRole}, {PodName: "mongodb-cluster-2", Role: ""}, {PodName: "mongodb-cluster-0", Role: primaryRole}},
+		},
+		{
+			name:    "service domain",
+			members: []mongogo.ReplicaSetMember{{Name: "mongodb-cluster-0.mongodb-cluster.default:27017", StateStr: "PRIMARY"}},
+			want:    []memberRole{{PodName: "mongodb-cluster-0", Role: primaryRole}},
+		},
+		{
+			name:    "external members do not relabel local pods",
+			members: []mongogo.ReplicaSetMember{local(0, "PRIMARY"), local(1, "SECONDARY"), external(0, "SECONDARY"), external(1, "RECOVERING")},
+			want:    []memberRole{{PodName: "mongodb-cluster-1", Role: secondaryRole}, {PodName: "mongodb-cluster-0", Role: primaryRole}},
+		},
+		{
+			name:    "external primary",
+			members: []mongogo.ReplicaSetMember{external(0, "PRIMARY"), local(0, "SECONDARY")},
+			want:    []memberRole{{PodName: "mongodb-cluster-0", Role: secondaryRole}},
+		},
+		{
+			name:    "member of another cluster",
+			members: []mongogo.ReplicaSetMember{{Name: "other-cluster-0.other-cluster.default:27017", StateStr: "PRIMARY"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getMongoClusterMemberRoles(cr, &mongogo.ReplicaSetStatus{Members: tt.members})
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("got roles %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
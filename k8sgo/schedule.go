@@ -0,0 +1,120 @@
+package k8sgo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronFieldBounds are the bounds of the minute, hour, day of month, month and day of week fields
+var cronFieldBounds = [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+
+// cronSchedule is a standard five fields cron expression
+type cronSchedule struct {
+	fields [5]map[int]bool
+	// anyDay and anyWeekday record unrestricted day fields, a day matches either field when both are restricted
+	anyDay     bool
+	anyWeekday bool
+}
+
+// parseCronSchedule is a method to parse a five fields cron expression made of numbers, "*", ranges, steps and lists, names
+// like MON or JAN and macros like @daily are not supported, the expression is evaluated in UTC
+func parseCronSchedule(expression string) (*cronSchedule, error) {
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields", expression)
+	}
+	schedule := &cronSchedule{anyDay: fields[2] == "*", anyWeekday: fields[4] == "*"}
+	for i, field := range fields {
+		values, err := parseCronField(field, cronFieldBounds[i][0], cronFieldBounds[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("cron expression %q: %v", expression, err)
+		}
+		schedule.fields[i] = values
+	}
+	// sunday is both 0 and 7
+	if schedule.fields[4][7] {
+		schedule.fields[4][0] = true
+	}
+	return schedule, nil
+}
+
+// parseCronField is a method to parse a cron field made of values, ranges and steps separated by commas
+func parseCronField(field string, min int, max int) (map[int]bool, error) {
+	values := map[int]bool{}
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if index := strings.Index(part, "/"); index >= 0 {
+			var err error
+			step, err = strconv.Atoi(part[index+1:])
+			if err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step in %q", part)
+			}
+			part = part[:index]
+		}
+		start, end := min, max
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			start, err = strconv.Atoi(bounds[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid value in %q", part)
+			}
+			end = start
+			if len(bounds) == 2 {
+				end, err = strconv.Atoi(bounds[1])
+				if err != nil {
+					return nil, fmt.Errorf("invalid value in %q", part)
+				}
+			} else if step > 1 {
+				end = max
+			}
+		}
+		if start < min || end > max || start > end {
+			return nil, fmt.Errorf("%q is out of range %d-%d", part, min, max)
+		}
+		for value := start; value <= end; value += step {
+			values[value] = true
+		}
+	}
+	return values, nil
+}
+
+// matches is a method to check if the minute of a time is scheduled
+func (s *cronSchedule) matches(t time.Time) bool {
+	return s.dayMatches(t) && s.fields[1][t.Hour()] && s.fields[0][t.Minute()]
+}
+
+// dayMatches is a method to check if the day of a time is scheduled
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	if !s.fields[3][int(t.Month())] {
+		return false
+	}
+	day := s.fields[2][t.Day()]
+	weekday := s.fields[4][int(t.Weekday())]
+	if s.anyDay || s.anyWeekday {
+		return day && weekday
+	}
+	return day || weekday
+}
+
+// last is a method to get the latest scheduled minute before now, nil is returned when nothing is scheduled within the lookback,
+// the days and hours which are not scheduled are skipped at once so a lookback of a year stays cheap
+func (s *cronSchedule) last(now time.Time, lookback time.Duration) *time.Time {
+	now = now.UTC().Truncate(time.Minute)
+	limit := now.Add(-lookback)
+	for t := now; !t.Before(limit); {
+		switch {
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Add(-time.Minute)
+		case !s.fields[1][t.Hour()]:
+			t = t.Truncate(time.Hour).Add(-time.Minute)
+		case s.fields[0][t.Minute()]:
+			return &t
+		default:
+			t = t.Add(-time.Minute)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package k8sgo
+
+import (
+	"testing"
+	"time"
+
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+)
+
+func TestParseCronSchedule(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		field      int
+		want       []int
+		wantErr    bool
+	}{
+		{name: "value", expression: "30 * * * *", field: 0, want: []int{30}},
+		{name: "range", expression: "0 7 * * 1-5", field: 4, want: []int{1, 2, 3, 4, 5}},
+		{name: "list", expression: "0 7,19 * * *", field: 1, want: []int{7, 19}},
+		{name: "step", expression: "*/15 * * * *", field: 0, want: []int{0, 15, 30, 45}},
+		{name: "step from a value", expression: "0 0 25/3 * *", field: 2, want: []int{25, 28, 31}},
+		{name: "range with step", expression: "0 0 * 1-6/2 *", field: 3, want: []int{1, 3, 5}},
+		{name: "sunday as 7", expression: "0 0 * * 7", field: 4, want: []int{0, 7}},
+		{name: "too few fields", expression: "0 7 * *", wantErr: true},
+		{name: "out of range", expression: "0 24 * * *", wantErr: true},
+		{name: "day zero", expression: "0 0 0 * *", wantErr: true},
+		{name: "reversed range", expression: "0 0 * * 5-1", wantErr: true},
+		{name: "invalid step", expression: "*/0 * * * *", wantErr: true},
+		{name: "names are not supported", expression: "0 7 * * MON", wantErr: true},
+		{name: "macros are not supported", expression: "@daily", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCronSchedule(tt.expression)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			values := got.fields[tt.field]
+			if len(values) != len(tt.want) {
+				t.Fatalf("got values %v, want %v", values, tt.want)
+			}
+			for _, value := range tt.want {
+				if !values[value] {
+					t.Errorf("got values %v, want %v", values, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestCronScheduleLast(t *testing.T) {
+	// a wednesday
+	now := time.Date(2022, time.September, 14, 12, 30, 45, 0, time.UTC)
+	date := func(year int, month time.Month, day int, hour int, minute int) *time.Time {
+		t := time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+		return &t
+	}
+	tests := []struct {
+		name       string
+		expression string
+		now        time.Time
+		want       *time.Time
+	}{
+		{name: "current minute", expression: "30 12 * * *", now: now, want: date(2022, time.September, 14, 12, 30)},
+		{name: "earlier today", expression: "0 7 * * *", now: now, want: date(2022, time.September, 14, 7, 0)},
+		{name: "yesterday", expression: "0 20 * * *", now: now, want: date(2022, time.September, 13, 20, 0)},
+		{name: "weekdays from monday", expression: "0 20 * * 1-5", now: *date(2022, time.September, 12, 8, 0), want: date(2022, time.September, 9, 20, 0)},
+		{name: "weekly", expression: "0 20 * * 4", now: now, want: date(2022, time.September, 8, 20, 0)},
+		{name: "monthly", expression: "0 2 15 * *", now: now, want: date(2022, time.August, 15, 2, 0)},
+		{name: "last days of the month", expression: "0 0 31 * *", now: now, want: date(2022, time.August, 31, 0, 0)},
+		{name: "yearly", expression: "0 0 1 10 *", now: now, want: date(2021, time.October, 1, 0, 0)},
+		{name: "day of month or day of week", expression: "0 0 1 * 0", now: now, want: date(2022, time.September, 11, 0, 0)},
+		{name: "other time zone", expression: "0 12 * * *", now: now.In(time.FixedZone("UTC+2", 2*3600)), want: date(2022, time.September, 14, 12, 0)},
+		{name: "beyond the lookback", expression: "0 0 29 2 *", now: now, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := parseCronSchedule(tt.expression)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := schedule.last(tt.now, hibernationLookback)
+			if (got == nil) != (tt.want == nil) || (got != nil && !got.Equal(*tt.want)) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHibernationRequested(t *testing.T) {
+	schedule := &opstreelabsinv1alpha1.MongoDBHibernationSchedule{Hibernate: "0 20 * * 1-5", Wake: "0 7 * * 1-5"}
+	tests := []struct {
+		name      string
+		hibernate bool
+		schedule  *opstreelabsinv1alpha1.MongoDBHibernationSchedule
+		now       time.Time
+		want      bool
+		wantErr   bool
+	}{
+		{name: "no schedule", now: time.Date(2022, time.September, 14, 22, 0, 0, 0, time.UTC)},
+		{name: "hibernate", hibernate: true, schedule: schedule, now: time.Date(2022, time.September, 14, 12, 0, 0, 0, time.UTC), want: true},
+		{name: "working hours", schedule: schedule, now: time.Date(2022, time.September, 14, 12, 0, 0, 0, time.UTC)},
+		{name: "evening", schedule: schedule, now: time.Date(2022, time.September, 14, 22, 0, 0, 0, time.UTC), want: true},
+		{name: "weekend", schedule: schedule, now: time.Date(2022, time.September, 18, 12, 0, 0, 0, time.UTC), want: true},
+		{name: "invalid schedule", schedule: &opstreelabsinv1alpha1.MongoDBHibernationSchedule{Hibernate: "0 20 * *", Wake: "0 7 * * *"}, now: time.Date(2022, time.September, 14, 12, 0, 0, 0, time.UTC), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := hibernationRequested(tt.hibernate, tt.schedule, tt.now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
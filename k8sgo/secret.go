@@ -2,10 +2,14 @@ package k8sgo
 
 import (
 	"context"
+	"github.com/thanhpk/randstr"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// defaultPasswordLength is the length of the passwords generated for the operator users
+const defaultPasswordLength = 16
+
 // secretsParameters is an interface for secret input
 type secretsParameters struct {
 	Name        string
@@ -35,26 +39,27 @@ func CreateSecret(params secretsParameters) error {
 // generateSecret is a method that will generate a secret interface
 func generateSecret(params secretsParameters) *corev1.Secret {
 	password := []byte(params.Password)
+	key := "password"
+	if params.SecretKey != "" {
+		key = params.SecretKey
+	}
 	secret := &corev1.Secret{
 		TypeMeta:   generateMetaInformation("Secret", "v1"),
 		ObjectMeta: params.SecretsMeta,
 		Data: map[string][]byte{
-			"password": password,
+			key: password,
 		},
 	}
 	AddOwnerRefToObject(secret, params.OwnerDef)
 	return secret
 }
 
-// getMongoDBPassword method will return the mongodb password
-func getMongoDBPassword(params secretsParameters) string {
-	logger := logGenerator(params.Name, params.Namespace, "Secret")
-	secretName, err := generateK8sClient().CoreV1().Secrets(params.Namespace).Get(context.TODO(), params.SecretName, metav1.GetOptions{})
-	if err != nil {
-		logger.Error(err, "Failed in getting existing secret for mongodb admin")
+// generatePassword is a method to generate a random password, alphanumeric characters are used when no charset is given
+func generatePassword(length int, charset string) string {
+	if charset == "" {
+		return randstr.String(length)
 	}
-	value := string(secretName.Data[params.SecretKey])
-	return value
+	return randstr.String(length, charset)
 }
 
 //nolint:gosimple
@@ -2,11 +2,11 @@ package k8sgo
 
 import (
 	"context"
-	"github.com/banzaicloud/k8s-objectmatcher/patch"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
 )
 
 const (
@@ -24,108 +24,49 @@ type serviceParameters struct {
 	HeadlessService bool
 	Port            int32
 	PortName        string
+	// PublishNotReady keeps DNS records of syncing members, which are not ready yet
+	PublishNotReady bool
+	// Selector overrides the labels used to select the pods of service
+	Selector                 map[string]string
+	TargetPort               int32
+	NodePort                 int32
+	ServiceType              corev1.ServiceType
+	LoadBalancerSourceRanges []string
+	ExternalTrafficPolicy    corev1.ServiceExternalTrafficPolicyType
 }
 
 // CreateOrUpdateService method will create or update MongoDB service
 func CreateOrUpdateService(params serviceParameters) error {
-	logger := logGenerator(params.ServiceMeta.Name, params.Namespace, "Service")
 	serviceDef := generateServiceDef(params)
-	storedService, err := getService(params.Namespace, params.ServiceMeta.Name)
-	if err != nil {
-		if errors.IsNotFound(err) {
-			if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(serviceDef); err != nil {
-				logger.Error(err, "Unable to patch MongoDB service with compare annotations")
-			}
-			return createService(params.Namespace, serviceDef)
-		}
+	// node ports and cluster IPs allocated by Kubernetes are not part of the applied fields, so they are kept
+	return serverSideApply(serviceDef, "Service", func(ctx context.Context, name string, data []byte, options metav1.PatchOptions) error {
+		_, err := generateK8sClient().CoreV1().Services(params.Namespace).Patch(ctx, name, types.ApplyPatchType, data, options)
 		return err
-	}
-	return patchService(storedService, serviceDef, params.Namespace)
-}
-
-// patchService will patch Kubernetes service
-func patchService(storedService *corev1.Service, newService *corev1.Service, namespace string) error {
-	logger := logGenerator(storedService.Name, namespace, "Service")
-	// adding meta fields
-	newService.ResourceVersion = storedService.ResourceVersion
-	newService.CreationTimestamp = storedService.CreationTimestamp
-	newService.ManagedFields = storedService.ManagedFields
-	newService.Spec.ClusterIP = storedService.Spec.ClusterIP
-
-	patchResult, err := patch.DefaultPatchMaker.Calculate(storedService, newService,
-		patch.IgnoreStatusFields(),
-		patch.IgnoreField("kind"),
-		patch.IgnoreField("apiVersion"),
-	)
-	if err != nil {
-		logger.Error(err, "Unable to patch MongoDB service with comparison object")
-		return err
-	}
-	if !patchResult.IsEmpty() {
-		for key, value := range storedService.Annotations {
-			if _, present := newService.Annotations[key]; !present {
-				newService.Annotations[key] = value
-			}
-		}
-		if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(newService); err != nil {
-			logger.Error(err, "Unable to patch MongoDB service with comparison object")
-			return err
-		}
-		logger.Info("Syncing MongoDB service with defined properties")
-		return updateService(namespace, newService)
-	}
-	logger.Info("MongoDB service is already in-sync")
-	return nil
-}
-
-// createService is a method to create service
-func createService(namespace string, service *corev1.Service) error {
-	logger := logGenerator(service.Name, namespace, "Service")
-	_, err := generateK8sClient().CoreV1().Services(namespace).Create(context.TODO(), service, metav1.CreateOptions{})
-	if err != nil {
-		logger.Error(err, "MongoDB service creation is failed")
-		return err
-	}
-	logger.Info("MongoDB service creation is successful")
-	return nil
-}
-
-// updateService is a method to update service
-func updateService(namespace string, service *corev1.Service) error {
-	logger := logGenerator(service.Name, namespace, "Service")
-	_, err := generateK8sClient().CoreV1().Services(namespace).Update(context.TODO(), service, metav1.UpdateOptions{})
-	if err != nil {
-		logger.Error(err, "MongoDB service updation is failed")
-		return err
-	}
-	logger.Info("MongoDB service updation is successful")
-	return nil
-}
-
-// getService is a method to get service
-func getService(namespace string, service string) (*corev1.Service, error) {
-	logger := logGenerator(service, namespace, "Service")
-	serviceInfo, err := generateK8sClient().CoreV1().Services(namespace).Get(context.TODO(), service, metav1.GetOptions{})
-	if err != nil {
-		logger.Info("MongoDB service get action is failed")
-		return nil, err
-	}
-	logger.Info("MongoDB service get action is successful")
-	return serviceInfo, nil
+	})
 }
 
 // generateServiceDef is a method to generate service definition
 func generateServiceDef(params serviceParameters) *corev1.Service {
+	selector := params.Selector
+	if selector == nil {
+		selector = params.Labels
+	}
+	targetPort := params.TargetPort
+	if targetPort == 0 {
+		targetPort = params.Port
+	}
 	service := &corev1.Service{
-		TypeMeta:   generateMetaInformation("Service", "core/v1"),
+		TypeMeta:   generateMetaInformation("Service", "v1"),
 		ObjectMeta: params.ServiceMeta,
 		Spec: corev1.ServiceSpec{
-			Selector: params.Labels,
+			Selector: selector,
+			Type:     params.ServiceType,
 			Ports: []corev1.ServicePort{
 				{
 					Name:       params.PortName,
 					Port:       params.Port,
-					TargetPort: intstr.FromInt(int(params.Port)),
+					TargetPort: intstr.FromInt(int(targetPort)),
+					NodePort:   params.NodePort,
 					Protocol:   corev1.ProtocolTCP,
 				},
 			},
@@ -134,6 +75,33 @@ func generateServiceDef(params serviceParameters) *corev1.Service {
 	if params.HeadlessService {
 		service.Spec.ClusterIP = "None"
 	}
+	if params.PublishNotReady {
+		service.Spec.PublishNotReadyAddresses = true
+	}
+	if params.ServiceType == corev1.ServiceTypeLoadBalancer {
+		service.Spec.LoadBalancerSourceRanges = params.LoadBalancerSourceRanges
+	}
+	if params.ServiceType == corev1.ServiceTypeNodePort || params.ServiceType == corev1.ServiceTypeLoadBalancer {
+		service.Spec.ExternalTrafficPolicy = params.ExternalTrafficPolicy
+	}
 	AddOwnerRefToObject(service, params.OwnerDef)
 	return service
 }
+
+// applyServiceSpec is a method to apply the user defined client service configuration on service inputs
+func applyServiceSpec(params *serviceParameters, spec *opstreelabsinv1alpha1.MongoDBService) {
+	if spec == nil {
+		return
+	}
+	params.ServiceType = spec.Type
+	params.LoadBalancerSourceRanges = spec.LoadBalancerSourceRanges
+	params.ExternalTrafficPolicy = spec.ExternalTrafficPolicy
+	params.ServiceMeta.Annotations = mergeMaps(spec.Annotations, params.ServiceMeta.Annotations)
+	if spec.Port != nil {
+		params.TargetPort = params.Port
+		params.Port = *spec.Port
+	}
+	if spec.NodePort != nil && spec.Type != corev1.ServiceTypeClusterIP && spec.Type != "" {
+		params.NodePort = *spec.NodePort
+	}
+}
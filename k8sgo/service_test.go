@@ -0,0 +1,37 @@
+package k8sgo
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+)
+
+func TestApplyServiceSpec(t *testing.T) {
+	port := int32(27018)
+	nodePort := int32(30017)
+	tests := []struct {
+		name           string
+		spec           *opstreelabsinv1alpha1.MongoDBService
+		wantType       corev1.ServiceType
+		wantPort       int32
+		wantTargetPort int32
+		wantNodePort   int32
+	}{
+		{name: "not configured", wantPort: mongoDBPort},
+		{name: "load balancer", spec: &opstreelabsinv1alpha1.MongoDBService{Type: corev1.ServiceTypeLoadBalancer}, wantType: corev1.ServiceTypeLoadBalancer, wantPort: mongoDBPort},
+		{name: "port", spec: &opstreelabsinv1alpha1.MongoDBService{Port: &port}, wantPort: port, wantTargetPort: mongoDBPort},
+		{name: "node port", spec: &opstreelabsinv1alpha1.MongoDBService{Type: corev1.ServiceTypeNodePort, NodePort: &nodePort}, wantType: corev1.ServiceTypeNodePort, wantPort: mongoDBPort, wantNodePort: nodePort},
+		{name: "node port of a ClusterIP service", spec: &opstreelabsinv1alpha1.MongoDBService{NodePort: &nodePort}, wantPort: mongoDBPort},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := serviceParameters{Port: mongoDBPort}
+			applyServiceSpec(&params, tt.spec)
+			if params.ServiceType != tt.wantType || params.Port != tt.wantPort || params.TargetPort != tt.wantTargetPort || params.NodePort != tt.wantNodePort {
+				t.Errorf("got type %q, port %d, target port %d, node port %d, want type %q, port %d, target port %d, node port %d",
+					params.ServiceType, params.Port, params.TargetPort, params.NodePort, tt.wantType, tt.wantPort, tt.wantTargetPort, tt.wantNodePort)
+			}
+		})
+	}
+}
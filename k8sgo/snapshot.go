@@ -0,0 +1,329 @@
+package k8sgo
+
+import (
+	"context"
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"mongodb-operator/metrics"
+	"mongodb-operator/mongo"
+	"sort"
+	"time"
+)
+
+const (
+	snapshotClusterLabel         = "mongodb.opstreelabs.in/cluster"
+	snapshotMemberAnnotation     = "mongodb.opstreelabs.in/member"
+	snapshotOptimeAnnotation     = "mongodb.opstreelabs.in/optime"
+	snapshotOptimeDateAnnotation = "mongodb.opstreelabs.in/optime-date"
+	snapshotReplicaSetAnnotation = "mongodb.opstreelabs.in/replica-set"
+	snapshotImageAnnotation      = "mongodb.opstreelabs.in/image"
+	defaultSnapshotIntervalHours = 24
+	defaultSnapshotRetention     = 7
+	snapshotCutTimeout           = 2 * time.Minute
+)
+
+var volumeSnapshotResource = schema.GroupVersionResource{Group: "snapshot.storage.k8s.io", Version: "v1", Resource: "volumesnapshots"}
+
+// CreateMongoClusterSnapshot is a method to take a VolumeSnapshot backup of a healthy secondary, when the snapshot interval is elapsed.
+// The member stays locked in a pending snapshot until the VolumeSnapshot is taken, it returns the pending and the taken snapshots.
+func CreateMongoClusterSnapshot(cr *opstreelabsinv1alpha1.MongoDBCluster, status *mongogo.ReplicaSetStatus) (*opstreelabsinv1alpha1.MongoDBSnapshotStatus, *opstreelabsinv1alpha1.MongoDBSnapshotStatus, error) {
+	defer metrics.StepTimer("cluster_snapshot")()
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Snapshot")
+	// a pending snapshot is completed even when snapshots are disabled, so its member is unlocked
+	if cr.Status.PendingSnapshot != nil {
+		return completeMongoClusterSnapshot(cr, cr.Status.PendingSnapshot)
+	}
+	snapshots, err := listMongoClusterSnapshots(cr)
+	if err != nil && !snapshotEnabled(cr) {
+		// the VolumeSnapshot API may not be installed when snapshots are not used
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	// the pending snapshot is lost from the status when its update fails or the operator restarts, it is then found from the
+	// VolumeSnapshots which are not taken yet, so their member is never left locked
+	if pending := getPendingSnapshot(snapshots); pending != nil {
+		logger.Info("MongoDB VolumeSnapshot is pending without status, completing it", "Snapshot", pending.Name, "Member", pending.Member)
+		return completeMongoClusterSnapshot(cr, pending)
+	}
+	if !snapshotEnabled(cr) {
+		return nil, nil, nil
+	}
+	interval := time.Duration(defaultSnapshotIntervalHours) * time.Hour
+	if cr.Spec.Backup.Snapshot.IntervalHours != nil {
+		interval = time.Duration(*cr.Spec.Backup.Snapshot.IntervalHours) * time.Hour
+	}
+	if len(snapshots) > 0 && time.Since(snapshots[len(snapshots)-1].GetCreationTimestamp().Time) < interval {
+		return nil, nil, nil
+	}
+	member, podName := getSnapshotMember(cr, status)
+	if member == nil {
+		logger.Info("No healthy secondary found to take a snapshot of MongoDB cluster")
+		return nil, nil, nil
+	}
+
+	mongoParams := getSnapshotMemberParams(cr, member.Name)
+	optime, err := mongogo.FsyncLockMember(mongoParams)
+	if err != nil {
+		logger.Error(err, "Unable to lock MongoDB member for snapshot", "Member", member.Name)
+		return nil, nil, err
+	}
+	snapshot := generateVolumeSnapshotDef(cr, podName, member.Name, optime)
+	created, err := generateK8sDynamicClient().Resource(volumeSnapshotResource).Namespace(cr.Namespace).Create(context.TODO(), snapshot, metav1.CreateOptions{})
+	if err != nil {
+		logger.Error(err, "MongoDB VolumeSnapshot creation is failed")
+		if unlockErr := mongogo.FsyncUnlockMember(mongoParams); unlockErr != nil {
+			logger.Error(unlockErr, "Unable to unlock MongoDB member after snapshot", "Member", member.Name)
+		}
+		return nil, nil, err
+	}
+	logger.Info("MongoDB VolumeSnapshot is requested", "Snapshot", created.GetName(), "Member", member.Name)
+	optimeDate, creationTime := metav1.NewTime(optime.Date), created.GetCreationTimestamp()
+	return &opstreelabsinv1alpha1.MongoDBSnapshotStatus{
+		Name:         created.GetName(),
+		Member:       member.Name,
+		Optime:       fmt.Sprintf("%d:%d", optime.Timestamp.T, optime.Timestamp.I),
+		OptimeDate:   &optimeDate,
+		CreationTime: &creationTime,
+	}, nil, nil
+}
+
+// completeMongoClusterSnapshot is a method to unlock the member of the pending snapshot once its VolumeSnapshot is taken, failed or timed out
+func completeMongoClusterSnapshot(cr *opstreelabsinv1alpha1.MongoDBCluster, pending *opstreelabsinv1alpha1.MongoDBSnapshotStatus) (*opstreelabsinv1alpha1.MongoDBSnapshotStatus, *opstreelabsinv1alpha1.MongoDBSnapshotStatus, error) {
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Snapshot")
+	snapshot, err := generateK8sDynamicClient().Resource(volumeSnapshotResource).Namespace(cr.Namespace).Get(context.TODO(), pending.Name, metav1.GetOptions{})
+	var taken bool
+	switch {
+	case errors.IsNotFound(err):
+		err = fmt.Errorf("volume snapshot %s was deleted before it was taken", pending.Name)
+	case err != nil:
+		return pending, nil, err
+	default:
+		taken, err = getSnapshotCut(snapshot)
+		if err == nil && !taken {
+			if pending.CreationTime != nil && time.Since(pending.CreationTime.Time) < snapshotCutTimeout {
+				return pending, nil, nil
+			}
+			err = fmt.Errorf("volume snapshot %s was not taken after %s", pending.Name, snapshotCutTimeout)
+		}
+	}
+	// the member must never stay locked, the unlock is retried while it fails
+	unlockErr := mongogo.FsyncUnlockMember(getSnapshotMemberParams(cr, pending.Member))
+	if unlockErr != nil {
+		logger.Error(unlockErr, "Unable to unlock MongoDB member after snapshot", "Member", pending.Member)
+		return pending, nil, unlockErr
+	}
+	if err != nil {
+		logger.Error(err, "MongoDB VolumeSnapshot was not taken, deleting it", "Snapshot", pending.Name)
+		deleteErr := generateK8sDynamicClient().Resource(volumeSnapshotResource).Namespace(cr.Namespace).Delete(context.TODO(), pending.Name, metav1.DeleteOptions{})
+		if deleteErr != nil && !errors.IsNotFound(deleteErr) {
+			logger.Error(deleteErr, "MongoDB VolumeSnapshot deletion is failed", "Snapshot", pending.Name)
+		}
+		return nil, nil, nil
+	}
+	logger.Info("MongoDB VolumeSnapshot is taken", "Snapshot", pending.Name, "Member", pending.Member)
+	if snapshotEnabled(cr) {
+		snapshots, err := listMongoClusterSnapshots(cr)
+		if err != nil {
+			return nil, pending, err
+		}
+		err = pruneMongoClusterSnapshots(cr, snapshots)
+		if err != nil {
+			return nil, pending, err
+		}
+	}
+	return nil, pending, nil
+}
+
+// getSnapshotMemberParams is a method to get the parameters of a direct connection to the snapshot member
+func getSnapshotMemberParams(cr *opstreelabsinv1alpha1.MongoDBCluster, member string) mongogo.MongoDBParameters {
+	mongoParams := mongogo.MongoDBParameters{
+		Namespace: cr.Namespace,
+		Name:      cr.ObjectMeta.Name,
+		SetupType: "standalone",
+	}
+	host := member
+	if ordinal, ok := getLocalMemberOrdinal(cr, member); ok {
+		// the member is reached with the service domain, the advertised domain may only resolve from the other site
+		host = mongogo.GetMongoNodeInfo(mongoParams, ordinal)
+	}
+	mongoParams.MongoURL = fmt.Sprintf("mongodb://%s:%s@%s/", cr.Spec.MongoDBSecurity.MongoDBAdminUser, getMongoClusterAdminPassword(cr), host)
+	return mongoParams
+}
+
+// snapshotEnabled is a method to check if VolumeSnapshot backups are enabled for MongoDB cluster
+func snapshotEnabled(cr *opstreelabsinv1alpha1.MongoDBCluster) bool {
+	return cr.Spec.Storage != nil && cr.Spec.Backup != nil && cr.Spec.Backup.Snapshot != nil && cr.Spec.Backup.Snapshot.Enabled
+}
+
+// getSnapshotMember is a method to pick the local healthy secondary with the most recent optime, hidden members are preferred. It returns
+// the member with its pod name, external members are never picked since the volume of their pod is in another Kubernetes cluster
+func getSnapshotMember(cr *opstreelabsinv1alpha1.MongoDBCluster, status *mongogo.ReplicaSetStatus) (*mongogo.ReplicaSetMember, string) {
+	var selected *mongogo.ReplicaSetMember
+	podName := ""
+	for index, member := range status.Members {
+		if member.StateStr != "SECONDARY" || member.Health != 1 {
+			continue
+		}
+		ordinal, ok := getLocalMemberOrdinal(cr, member.Name)
+		if !ok {
+			continue
+		}
+		if selected == nil || (member.Hidden && !selected.Hidden) ||
+			(member.Hidden == selected.Hidden && member.OptimeDate.After(selected.OptimeDate)) {
+			selected = &status.Members[index]
+			podName = fmt.Sprintf("%s-cluster-%d", cr.ObjectMeta.Name, ordinal)
+		}
+	}
+	return selected, podName
+}
+
+// generateVolumeSnapshotDef is a method to generate the VolumeSnapshot of a MongoDB member volume
+func generateVolumeSnapshotDef(cr *opstreelabsinv1alpha1.MongoDBCluster, podName string, member string, optime *mongogo.MemberOptime) *unstructured.Unstructured {
+	appName := fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster")
+	labels := mergeMaps(getMongoDBClusterMetadata(cr, "").Labels, map[string]string{
+		"app":                appName,
+		"mongodb_setup":      "cluster",
+		snapshotClusterLabel: cr.ObjectMeta.Name,
+	})
+	annotations := mergeMaps(getMongoDBClusterMetadata(cr, "").Annotations, map[string]string{
+		snapshotMemberAnnotation:     member,
+		snapshotOptimeAnnotation:     fmt.Sprintf("%d:%d", optime.Timestamp.T, optime.Timestamp.I),
+		snapshotOptimeDateAnnotation: optime.Date.UTC().Format(time.RFC3339),
+		snapshotReplicaSetAnnotation: cr.ObjectMeta.Name,
+		snapshotImageAnnotation:      cr.Spec.KubernetesConfig.Image,
+	})
+	spec := map[string]interface{}{
+		"source": map[string]interface{}{
+			// claims of a StatefulSet are named <template>-<pod>
+			"persistentVolumeClaimName": fmt.Sprintf("%s-%s", appName, podName),
+		},
+	}
+	if cr.Spec.Backup.Snapshot.VolumeSnapshotClassName != nil {
+		spec["volumeSnapshotClassName"] = *cr.Spec.Backup.Snapshot.VolumeSnapshotClassName
+	}
+	snapshot := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "snapshot.storage.k8s.io/v1",
+		"kind":       "VolumeSnapshot",
+		"spec":       spec,
+	}}
+	snapshot.SetName(fmt.Sprintf("%s-%s", podName, time.Now().UTC().Format("20060102150405")))
+	snapshot.SetNamespace(cr.Namespace)
+	snapshot.SetLabels(labels)
+	snapshot.SetAnnotations(annotations)
+	// snapshots are backups, they are kept when the MongoDB cluster is deleted
+	return snapshot
+}
+
+// getPendingSnapshot is a method to get the oldest VolumeSnapshot which is not taken yet, its member and optime are read from its annotations
+func getPendingSnapshot(snapshots []unstructured.Unstructured) *opstreelabsinv1alpha1.MongoDBSnapshotStatus {
+	for _, snapshot := range snapshots {
+		if _, found, _ := unstructured.NestedString(snapshot.Object, "status", "creationTime"); found {
+			continue
+		}
+		annotations := snapshot.GetAnnotations()
+		if annotations[snapshotMemberAnnotation] == "" {
+			continue
+		}
+		creationTime := snapshot.GetCreationTimestamp()
+		pending := &opstreelabsinv1alpha1.MongoDBSnapshotStatus{
+			Name:         snapshot.GetName(),
+			Member:       annotations[snapshotMemberAnnotation],
+			Optime:       annotations[snapshotOptimeAnnotation],
+			CreationTime: &creationTime,
+		}
+		if optimeDate, err := time.Parse(time.RFC3339, annotations[snapshotOptimeDateAnnotation]); err == nil {
+			pending.OptimeDate = &metav1.Time{Time: optimeDate}
+		}
+		return pending
+	}
+	return nil
+}
+
+// getSnapshotCut is a method to check if the point-in-time of a VolumeSnapshot is taken
+func getSnapshotCut(snapshot *unstructured.Unstructured) (bool, error) {
+	if message, found, _ := unstructured.NestedString(snapshot.Object, "status", "error", "message"); found {
+		return false, fmt.Errorf("volume snapshot %s failed: %s", snapshot.GetName(), message)
+	}
+	_, found, _ := unstructured.NestedString(snapshot.Object, "status", "creationTime")
+	return found, nil
+}
+
+// listMongoClusterSnapshots is a method to list the VolumeSnapshots of MongoDB cluster, the oldest first
+func listMongoClusterSnapshots(cr *opstreelabsinv1alpha1.MongoDBCluster) ([]unstructured.Unstructured, error) {
+	list, err := generateK8sDynamicClient().Resource(volumeSnapshotResource).Namespace(cr.Namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", snapshotClusterLabel, cr.ObjectMeta.Name),
+	})
+	if err != nil {
+		return nil, err
+	}
+	snapshots := list.Items
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].GetCreationTimestamp().Time.Before(snapshots[j].GetCreationTimestamp().Time)
+	})
+	return snapshots, nil
+}
+
+// pruneMongoClusterSnapshots is a method to delete the oldest VolumeSnapshots above the retention
+func pruneMongoClusterSnapshots(cr *opstreelabsinv1alpha1.MongoDBCluster, snapshots []unstructured.Unstructured) error {
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Snapshot")
+	retention := defaultSnapshotRetention
+	if cr.Spec.Backup.Snapshot.Retention != nil {
+		retention = int(*cr.Spec.Backup.Snapshot.Retention)
+	}
+	for index := 0; index < len(snapshots)-retention; index++ {
+		err := generateK8sDynamicClient().Resource(volumeSnapshotResource).Namespace(cr.Namespace).Delete(context.TODO(), snapshots[index].GetName(), metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		logger.Info("MongoDB VolumeSnapshot is deleted by retention", "Snapshot", snapshots[index].GetName())
+	}
+	return nil
+}
+
+// seedMongoClusterMembers is a method to create the volumes of new MongoDB members from the latest snapshot before scaling up
+func seedMongoClusterMembers(cr *opstreelabsinv1alpha1.MongoDBCluster, params pvcParameters) error {
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "PersistentVolumeClaim")
+	if !snapshotEnabled(cr) || !cr.Spec.Backup.Snapshot.SeedNewMembers || cr.Status.LatestSnapshot == nil {
+		return nil
+	}
+	appName := fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster")
+	stored, err := GetStateFulSet(cr.Namespace, appName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	for ordinal := int(*stored.Spec.Replicas); ordinal < int(*cr.Spec.MongoDBClusterSize); ordinal++ {
+		claimName := fmt.Sprintf("%s-%s-%d", params.Name, appName, ordinal)
+		_, err := generateK8sClient().CoreV1().PersistentVolumeClaims(cr.Namespace).Get(context.TODO(), claimName, metav1.GetOptions{})
+		if err == nil {
+			continue
+		}
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		claim := generatePersistentVolumeTemplate(params)
+		claim.Name = claimName
+		claim.Namespace = cr.Namespace
+		claim.Spec.DataSource = &corev1.TypedLocalObjectReference{
+			APIGroup: &volumeSnapshotResource.Group,
+			Kind:     "VolumeSnapshot",
+			Name:     cr.Status.LatestSnapshot.Name,
+		}
+		_, err = generateK8sClient().CoreV1().PersistentVolumeClaims(cr.Namespace).Create(context.TODO(), &claim, metav1.CreateOptions{})
+		if err != nil {
+			logger.Error(err, "MongoDB member volume creation from snapshot is failed", "Claim", claimName)
+			return err
+		}
+		logger.Info("MongoDB member volume is created from snapshot", "Claim", claimName, "Snapshot", cr.Status.LatestSnapshot.Name)
+	}
+	return nil
+}
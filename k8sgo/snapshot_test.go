@@ -0,0 +1,164 @@
+package k8sgo
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	mongogo "mongodb-operator/mongo"
+)
+
+func TestGetSnapshotCut(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  map[string]interface{}
+		want    bool
+		wantErr bool
+	}{
+		{name: "no status"},
+		{name: "not taken yet", status: map[string]interface{}{"readyToUse": false}},
+		{name: "taken", status: map[string]interface{}{"creationTime": "2022-03-01T10:00:00Z", "readyToUse": false}, want: true},
+		{name: "failed", status: map[string]interface{}{"error": map[string]interface{}{"message": "quota exceeded"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot := &unstructured.Unstructured{Object: map[string]interface{}{}}
+			if tt.status != nil {
+				snapshot.Object["status"] = tt.status
+			}
+			got, err := getSnapshotCut(snapshot)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got taken %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetSnapshotMember(t *testing.T) {
+	now := time.Now()
+	size := int32(3)
+	cr := &opstreelabsinv1alpha1.MongoDBCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "mongodb", Namespace: "default"},
+		Spec: opstreelabsinv1alpha1.MongoDBClusterSpec{
+			MongoDBClusterSize: &size,
+			DisasterRecovery:   &opstreelabsinv1alpha1.MongoDBDisasterRecovery{AdvertisedDomain: "primary.example.com"},
+		},
+	}
+	a := "mongodb-cluster-0.mongodb-cluster.default:27017"
+	b := "mongodb-cluster-1.primary.example.com:27017"
+	external := "mongodb-cluster-2.dr.example.com:27017"
+	tests := []struct {
+		name    string
+		members []mongogo.ReplicaSetMember
+		want    string
+		wantPod string
+	}{
+		{name: "no secondary", members: []mongogo.ReplicaSetMember{{Name: a, StateStr: "PRIMARY", Health: 1}}},
+		{name: "unhealthy secondary", members: []mongogo.ReplicaSetMember{{Name: a, StateStr: "SECONDARY", Health: 0}}},
+		{
+			name: "most recent secondary",
+			members: []mongogo.ReplicaSetMember{
+				{Name: a, StateStr: "SECONDARY", Health: 1, OptimeDate: now.Add(-time.Minute)},
+				{Name: b, StateStr: "SECONDARY", Health: 1, OptimeDate: now},
+			},
+			want:    b,
+			wantPod: "mongodb-cluster-1",
+		},
+		{
+			name: "hidden first",
+			members: []mongogo.ReplicaSetMember{
+				{Name: b, StateStr: "SECONDARY", Health: 1, OptimeDate: now},
+				{Name: a, StateStr: "SECONDARY", Health: 1, OptimeDate: now.Add(-time.Minute), Hidden: true},
+			},
+			want:    a,
+			wantPod: "mongodb-cluster-0",
+		},
+		{
+			name: "external secondary is never picked",
+			members: []mongogo.ReplicaSetMember{
+				{Name: a, StateStr: "SECONDARY", Health: 1, OptimeDate: now.Add(-time.Minute)},
+				{Name: external, StateStr: "SECONDARY", Health: 1, OptimeDate: now, Hidden: true},
+			},
+			want:    a,
+			wantPod: "mongodb-cluster-0",
+		},
+		{name: "only external secondaries", members: []mongogo.ReplicaSetMember{{Name: external, StateStr: "SECONDARY", Health: 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ""
+			member, podName := getSnapshotMember(cr, &mongogo.ReplicaSetStatus{Members: tt.members})
+			if member != nil {
+				got = member.Name
+			}
+			if got != tt.want || podName != tt.wantPod {
+				t.Errorf("got member %q with pod %q, want %q with pod %q", got, podName, tt.want, tt.wantPod)
+			}
+		})
+	}
+}
+
+func TestGetPendingSnapshot(t *testing.T) {
+	snapshot := func(name string, member string, status map[string]interface{}) unstructured.Unstructured {
+		object := unstructured.Unstructured{Object: map[string]interface{}{}}
+		object.SetName(name)
+		if member != "" {
+			object.SetAnnotations(map[string]string{
+				snapshotMemberAnnotation:     member,
+				snapshotOptimeAnnotation:     "1646128800:1",
+				snapshotOptimeDateAnnotation: "2022-03-01T10:00:00Z",
+			})
+		}
+		if status != nil {
+			object.Object["status"] = status
+		}
+		return object
+	}
+	taken := map[string]interface{}{"creationTime": "2022-03-01T10:00:00Z", "readyToUse": true}
+	tests := []struct {
+		name       string
+		snapshots  []unstructured.Unstructured
+		want       string
+		wantMember string
+	}{
+		{name: "no snapshot"},
+		{name: "all taken", snapshots: []unstructured.Unstructured{snapshot("s0", "m0", taken), snapshot("s1", "m1", taken)}},
+		{
+			name:       "not taken yet",
+			snapshots:  []unstructured.Unstructured{snapshot("s0", "m0", taken), snapshot("s1", "m1", map[string]interface{}{"readyToUse": false})},
+			want:       "s1",
+			wantMember: "m1",
+		},
+		{name: "no status", snapshots: []unstructured.Unstructured{snapshot("s0", "m0", taken), snapshot("s1", "m1", nil)}, want: "s1", wantMember: "m1"},
+		{
+			name:       "failed",
+			snapshots:  []unstructured.Unstructured{snapshot("s0", "m0", map[string]interface{}{"error": map[string]interface{}{"message": "quota exceeded"}})},
+			want:       "s0",
+			wantMember: "m0",
+		},
+		{name: "oldest first", snapshots: []unstructured.Unstructured{snapshot("s0", "m0", nil), snapshot("s1", "m1", nil)}, want: "s0", wantMember: "m0"},
+		{name: "not taken by the operator", snapshots: []unstructured.Unstructured{snapshot("s0", "", nil)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getPendingSnapshot(tt.snapshots)
+			if tt.want == "" {
+				if got != nil {
+					t.Errorf("got pending snapshot %s, want none", got.Name)
+				}
+				return
+			}
+			if got == nil || got.Name != tt.want || got.Member != tt.wantMember {
+				t.Fatalf("got pending snapshot %+v, want %s of member %s", got, tt.want, tt.wantMember)
+			}
+			if got.Optime != "1646128800:1" || got.OptimeDate == nil || !got.OptimeDate.Time.Equal(time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)) {
+				t.Errorf("got optime %s at %v, want the optime of the annotations", got.Optime, got.OptimeDate)
+			}
+		})
+	}
+}
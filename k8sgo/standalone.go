@@ -2,12 +2,13 @@ package k8sgo
 
 import (
 	"fmt"
-	"github.com/thanhpk/randstr"
 	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"mongodb-operator/metrics"
 )
 
 // CreateMongoStandaloneService is a method to create standalone service for MongoDB
 func CreateMongoStandaloneService(cr *opstreelabsinv1alpha1.MongoDB) error {
+	defer metrics.StepTimer("standalone_service")()
 	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "Service")
 	appName := fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "standalone")
 	labels := map[string]string{
@@ -16,7 +17,7 @@ func CreateMongoStandaloneService(cr *opstreelabsinv1alpha1.MongoDB) error {
 		"role":          "standalone",
 	}
 	params := serviceParameters{
-		ServiceMeta:     generateObjectMetaInformation(appName, cr.Namespace, labels, generateAnnotations()),
+		ServiceMeta:     generateObjectMetaInformation(appName, cr.Namespace, labels, generateAnnotations(), getMongoDBStandaloneMetadata(cr, serviceObject)),
 		OwnerDef:        mongoAsOwner(cr),
 		Namespace:       cr.Namespace,
 		Labels:          labels,
@@ -30,28 +31,64 @@ func CreateMongoStandaloneService(cr *opstreelabsinv1alpha1.MongoDB) error {
 		logger.Error(err, "Cannot create standalone Service for MongoDB")
 		return err
 	}
-	monitoringParams := serviceParameters{
-		ServiceMeta:     generateObjectMetaInformation(fmt.Sprintf("%s-%s", appName, "metrics"), cr.Namespace, labels, generateAnnotations()),
-		OwnerDef:        mongoAsOwner(cr),
-		Namespace:       cr.Namespace,
-		Labels:          labels,
-		Annotations:     generateAnnotations(),
-		HeadlessService: false,
-		Port:            mongoDBMonitoringPort,
-		PortName:        "metrics",
+	if cr.Spec.Service != nil {
+		clientParams := serviceParameters{
+			ServiceMeta: generateObjectMetaInformation(fmt.Sprintf("%s-%s", appName, "client"), cr.Namespace, labels, generateAnnotations(), getMongoDBStandaloneMetadata(cr, serviceObject)),
+			OwnerDef:    mongoAsOwner(cr),
+			Namespace:   cr.Namespace,
+			Labels:      labels,
+			Annotations: generateAnnotations(),
+			Port:        mongoDBPort,
+			PortName:    "mongo",
+		}
+		applyServiceSpec(&clientParams, cr.Spec.Service)
+		err = CreateOrUpdateService(clientParams)
+		if err != nil {
+			logger.Error(err, "Cannot create standalone client Service for MongoDB")
+			return err
+		}
 	}
-	err = CreateOrUpdateService(monitoringParams)
-	if err != nil {
-		logger.Error(err, "Cannot create standalone metrics Service for MongoDB")
-		return err
+	if cr.Spec.MongoDBMonitoring != nil {
+		monitoringParams := serviceParameters{
+			ServiceMeta:     generateObjectMetaInformation(fmt.Sprintf("%s-%s", appName, "metrics"), cr.Namespace, labels, generateAnnotations(), getMongoDBStandaloneMetadata(cr, serviceObject)),
+			OwnerDef:        mongoAsOwner(cr),
+			Namespace:       cr.Namespace,
+			Labels:          labels,
+			Annotations:     generateAnnotations(),
+			HeadlessService: false,
+			Port:            mongoDBMonitoringPort,
+			PortName:        "metrics",
+		}
+		err = CreateOrUpdateService(monitoringParams)
+		if err != nil {
+			logger.Error(err, "Cannot create standalone metrics Service for MongoDB")
+			return err
+		}
 	}
 	return nil
 }
 
 // CreateMongoStandaloneSetup is a method to create standalone statefulset for MongoDB
 func CreateMongoStandaloneSetup(cr *opstreelabsinv1alpha1.MongoDB) error {
+	defer metrics.StepTimer("standalone_statefulset")()
 	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "StatefulSet")
-	err := CreateOrUpdateStateFul(getMongoDBStandaloneParams(cr))
+	err := validateImageFlavor(cr.Spec.KubernetesConfig.ImageFlavor, cr.Spec.MongoDBAdditionalConfig)
+	if err != nil {
+		logger.Error(err, "Invalid configuration for the image flavor of MongoDB")
+		return err
+	}
+	err = validateVaultCredential(cr.Spec.KubernetesConfig.ImageFlavor, cr.Spec.MongoDBSecurity)
+	if err != nil {
+		logger.Error(err, "Invalid vault configuration of MongoDB")
+		return err
+	}
+	params := getMongoDBStandaloneParams(cr)
+	err = createMongodConfig(cr.Spec.MongodConfig, &params)
+	if err != nil {
+		logger.Error(err, "Cannot create mongod configuration for MongoDB")
+		return err
+	}
+	err = CreateOrUpdateStateFul(params)
 	if err != nil {
 		logger.Error(err, "Cannot create standalone StatefulSet for MongoDB")
 		return err
@@ -61,6 +98,7 @@ func CreateMongoStandaloneSetup(cr *opstreelabsinv1alpha1.MongoDB) error {
 
 // CreateMongoMonitoringSecret is a method to create secret for monitoring
 func CreateMongoMonitoringSecret(cr *opstreelabsinv1alpha1.MongoDB) error {
+	defer metrics.StepTimer("standalone_monitoring_secret")()
 	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "Secret")
 	err := CreateSecret(getMongoDBSecretParams(cr))
 	if err != nil {
@@ -72,7 +110,7 @@ func CreateMongoMonitoringSecret(cr *opstreelabsinv1alpha1.MongoDB) error {
 
 // getMongoDBSecretParams is a method to create secret for MongoDB Monitoring
 func getMongoDBSecretParams(cr *opstreelabsinv1alpha1.MongoDB) secretsParameters {
-	password := randstr.String(16)
+	password := generatePassword(defaultPasswordLength, "")
 	appName := fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "standalone-monitoring")
 	labels := map[string]string{
 		"app":           appName,
@@ -80,7 +118,7 @@ func getMongoDBSecretParams(cr *opstreelabsinv1alpha1.MongoDB) secretsParameters
 		"role":          "standalone",
 	}
 	params := secretsParameters{
-		SecretsMeta: generateObjectMetaInformation(appName, cr.Namespace, labels, generateAnnotations()),
+		SecretsMeta: generateObjectMetaInformation(appName, cr.Namespace, labels, generateAnnotations(), getMongoDBStandaloneMetadata(cr, "")),
 		OwnerDef:    mongoAsOwner(cr),
 		Namespace:   cr.Namespace,
 		Labels:      labels,
@@ -94,49 +132,71 @@ func getMongoDBSecretParams(cr *opstreelabsinv1alpha1.MongoDB) secretsParameters
 // getMongoDBStandaloneParams is a method to generate params for standalone
 func getMongoDBStandaloneParams(cr *opstreelabsinv1alpha1.MongoDB) statefulSetParameters {
 	replicas := int32(1)
+	if IsHibernated(cr.Status.Hibernation) {
+		replicas = 0
+	}
 	trueProperty := true
 	falseProperty := false
 	appName := fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "standalone")
-	monitoringSecretName := fmt.Sprintf("%s-%s", appName, "monitoring")
 	labels := map[string]string{
 		"app":           appName,
 		"mongodb_setup": "standalone",
 		"role":          "standalone",
 	}
 	params := statefulSetParameters{
-		StatefulSetMeta: generateObjectMetaInformation(appName, cr.Namespace, labels, generateAnnotations()),
+		StatefulSetMeta: generateObjectMetaInformation(appName, cr.Namespace, labels, generateAnnotations(), getMongoDBStandaloneMetadata(cr, statefulSetObject)),
+		CommonMetadata:  getMongoDBStandaloneMetadata(cr, ""),
 		OwnerDef:        mongoAsOwner(cr),
 		Namespace:       cr.Namespace,
 		ContainerParams: containerParameters{
-			Image:           cr.Spec.KubernetesConfig.Image,
-			ImagePullPolicy: cr.Spec.KubernetesConfig.ImagePullPolicy,
-			Resources:       cr.Spec.KubernetesConfig.Resources,
-			MongoSetupType:  "standalone",
+			Image:             cr.Spec.KubernetesConfig.Image,
+			ImagePullPolicy:   cr.Spec.KubernetesConfig.ImagePullPolicy,
+			Resources:         cr.Spec.KubernetesConfig.Resources,
+			LivenessProbe:     cr.Spec.KubernetesConfig.LivenessProbe,
+			ReadinessProbe:    cr.Spec.KubernetesConfig.ReadinessProbe,
+			StartupProbe:      cr.Spec.KubernetesConfig.StartupProbe,
+			ExtraVolumeMounts: cr.Spec.KubernetesConfig.ExtraVolumeMounts,
+			ExtraEnv:          cr.Spec.KubernetesConfig.ExtraEnv,
+			SecurityContext:   cr.Spec.KubernetesConfig.ContainerSecurityContext,
+			MongoSetupType:    "standalone",
+			ImageFlavor:       cr.Spec.KubernetesConfig.ImageFlavor,
 		},
-		Replicas:          &replicas,
-		Labels:            labels,
-		Annotations:       generateAnnotations(),
-		NodeSelector:      cr.Spec.KubernetesConfig.NodeSelector,
-		Affinity:          cr.Spec.KubernetesConfig.Affinity,
-		PriorityClassName: cr.Spec.KubernetesConfig.PriorityClassName,
-		Tolerations:       cr.Spec.KubernetesConfig.Tolerations,
-		SecurityContext:   cr.Spec.KubernetesConfig.SecurityContext,
+		Replicas:               &replicas,
+		Labels:                 labels,
+		Annotations:            generateAnnotations(),
+		NodeSelector:           cr.Spec.KubernetesConfig.NodeSelector,
+		Affinity:               cr.Spec.KubernetesConfig.Affinity,
+		PriorityClassName:      cr.Spec.KubernetesConfig.PriorityClassName,
+		Tolerations:            cr.Spec.KubernetesConfig.Tolerations,
+		SecurityContext:        cr.Spec.KubernetesConfig.SecurityContext,
+		PodLabels:              mergeMaps(getMongoDBStandaloneMetadata(cr, podObject).Labels, cr.Spec.KubernetesConfig.PodLabels),
+		PodAnnotations:         mergeMaps(getMongoDBStandaloneMetadata(cr, podObject).Annotations, cr.Spec.KubernetesConfig.PodAnnotations),
+		Sidecars:               cr.Spec.KubernetesConfig.Sidecars,
+		InitContainers:         cr.Spec.KubernetesConfig.InitContainers,
+		ServiceAccountName:     cr.Spec.KubernetesConfig.ServiceAccountName,
+		TerminationGracePeriod: cr.Spec.KubernetesConfig.TerminationGracePeriodSeconds,
 	}
 
 	if cr.Spec.KubernetesConfig.ImagePullSecret != nil {
 		params.ImagePullSecret = cr.Spec.KubernetesConfig.ImagePullSecret
 	}
+	if cr.Spec.KubernetesConfig.ExtraVolumes != nil {
+		params.ExtraVolumes = &cr.Spec.KubernetesConfig.ExtraVolumes
+	}
 	if cr.Spec.MongoDBSecurity != nil {
 		params.ContainerParams.MongoDBUser = &cr.Spec.MongoDBSecurity.MongoDBAdminUser
-		params.ContainerParams.SecretName = cr.Spec.MongoDBSecurity.SecretRef.Name
-		params.ContainerParams.SecretKey = cr.Spec.MongoDBSecurity.SecretRef.Key
+		adminCredential := getMongoStandaloneAdminCredentialProvider(cr)
+		params.ContainerParams.AdminCredential = adminCredential
+		params.PodAnnotations = mergeMaps(params.PodAnnotations, adminCredential.GetPodCredential("").Annotations)
 	}
 	if cr.Spec.MongoDBMonitoring != nil {
 		params.ContainerParams.MongoDBMonitoring = &trueProperty
-		params.ContainerParams.MonitoringSecret = &monitoringSecretName
+		params.ContainerParams.MonitoringCredential = getMongoStandaloneMonitoringCredentialProvider(cr)
 		params.ContainerParams.MonitoringResources = cr.Spec.MongoDBMonitoring.Resources
 		params.ContainerParams.MonitoringImage = cr.Spec.MongoDBMonitoring.Image
 		params.ContainerParams.MonitoringImagePullPolicy = &cr.Spec.MongoDBMonitoring.ImagePullPolicy
+		params.ContainerParams.MonitoringLivenessProbe = cr.Spec.MongoDBMonitoring.LivenessProbe
+		params.ContainerParams.MonitoringReadinessProbe = cr.Spec.MongoDBMonitoring.ReadinessProbe
 	}
 	if cr.Spec.MongoDBAdditionalConfig != nil {
 		params.ContainerParams.AdditonalConfig = cr.Spec.MongoDBAdditionalConfig
@@ -147,8 +207,8 @@ func getMongoDBStandaloneParams(cr *opstreelabsinv1alpha1.MongoDB) statefulSetPa
 		params.PVCParameters = pvcParameters{
 			Name:             appName,
 			Namespace:        cr.Namespace,
-			Labels:           labels,
-			Annotations:      generateAnnotations(),
+			Labels:           mergeMaps(getMongoDBStandaloneMetadata(cr, pvcObject).Labels, labels),
+			Annotations:      getMongoDBStandaloneMetadata(cr, pvcObject).Annotations,
 			StorageSize:      cr.Spec.Storage.StorageSize,
 			StorageClassName: cr.Spec.Storage.StorageClassName,
 			AccessModes:      cr.Spec.Storage.AccessModes,
@@ -158,3 +218,8 @@ func getMongoDBStandaloneParams(cr *opstreelabsinv1alpha1.MongoDB) statefulSetPa
 	}
 	return params
 }
+
+// getMongoDBStandaloneMetadata is a method to get the user defined labels and annotations for a type of generated object
+func getMongoDBStandaloneMetadata(cr *opstreelabsinv1alpha1.MongoDB, object string) opstreelabsinv1alpha1.ObjectMetadata {
+	return generateUserMetadata(cr.Spec.CommonLabels, cr.Spec.CommonAnnotations, cr.Spec.MetadataOverrides, object)
+}
@@ -8,28 +8,38 @@ import (
 	resource "k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
-	"github.com/iamabhishek-dubey/k8s-objectmatcher/patch"
 	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/types"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
 )
 
 // statefulSetParameters is the input struct for MongoDB statefulset
 type statefulSetParameters struct {
-	StatefulSetMeta   metav1.ObjectMeta
-	OwnerDef          metav1.OwnerReference
-	Namespace         string
-	ContainerParams   containerParameters
-	Labels            map[string]string
-	Annotations       map[string]string
-	Replicas          *int32
-	PVCParameters     pvcParameters
-	ExtraVolumes      *[]corev1.Volume
-	ImagePullSecret   *string
-	Affinity          *corev1.Affinity
-	NodeSelector      map[string]string
-	Tolerations       *[]corev1.Toleration
-	PriorityClassName string
-	AdditionalConfig  *string
-	SecurityContext   *corev1.PodSecurityContext
+	StatefulSetMeta           metav1.ObjectMeta
+	OwnerDef                  metav1.OwnerReference
+	Namespace                 string
+	ContainerParams           containerParameters
+	Labels                    map[string]string
+	Annotations               map[string]string
+	Replicas                  *int32
+	PVCParameters             pvcParameters
+	ExtraVolumes              *[]corev1.Volume
+	ImagePullSecret           *string
+	Affinity                  *corev1.Affinity
+	NodeSelector              map[string]string
+	Tolerations               *[]corev1.Toleration
+	PriorityClassName         string
+	AdditionalConfig          *string
+	SecurityContext           *corev1.PodSecurityContext
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint
+	MongodConfig              *string
+	PodLabels                 map[string]string
+	PodAnnotations            map[string]string
+	Sidecars                  []corev1.Container
+	InitContainers            []corev1.Container
+	ServiceAccountName        string
+	CommonMetadata            opstreelabsinv1alpha1.ObjectMetadata
+	TerminationGracePeriod    *int64
 }
 
 // pvcParameters is the structure for MongoDB PVC
@@ -45,80 +55,18 @@ type pvcParameters struct {
 
 // CreateOrUpdateStateFul method will create or update StatefulSet
 func CreateOrUpdateStateFul(params statefulSetParameters) error {
-	logger := logGenerator(params.StatefulSetMeta.Name, params.Namespace, "StatefulSet")
-	storedStateful, err := GetStateFulSet(params.Namespace, params.StatefulSetMeta.Name)
 	statefulSetDef := generateStatefulSetDef(params)
-	if err != nil {
-		if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(statefulSetDef); err != nil {
-			logger.Error(err, "Unable to patch redis statefulset with comparison object")
-			return err
-		}
-		if errors.IsNotFound(err) {
-			return createStateFulSet(params.Namespace, statefulSetDef)
-		}
-		return err
-	}
-	return patchStateFulSet(storedStateful, statefulSetDef, params.Namespace)
-}
-
-// patchStateFulSet will patch Statefulset
-func patchStateFulSet(storedStateful *appsv1.StatefulSet, newStateful *appsv1.StatefulSet, namespace string) error {
-	logger := logGenerator(storedStateful.Name, namespace, "StatefulSet")
-	// adding meta information
-	newStateful.ResourceVersion = storedStateful.ResourceVersion
-	newStateful.CreationTimestamp = storedStateful.CreationTimestamp
-	newStateful.ManagedFields = storedStateful.ManagedFields
-	patchResult, err := patch.DefaultPatchMaker.Calculate(storedStateful, newStateful,
-		patch.IgnoreStatusFields(),
-		patch.IgnoreVolumeClaimTemplateTypeMetaAndStatus(),
-		patch.IgnorePersistenVolumeFields(),
-		patch.IgnoreField("kind"),
-		patch.IgnoreField("apiVersion"),
-		patch.IgnoreField("metadata"),
-	)
-	if err != nil {
-		logger.Error(err, "Unable to patch mongodb statefulset with comparison object")
-		return err
-	}
-	if !patchResult.IsEmpty() {
-		logger.Info("Changes in statefulset Detected, Updating...", "patch", string(patchResult.Patch))
-		for key, value := range storedStateful.Annotations {
-			if _, present := newStateful.Annotations[key]; !present {
-				newStateful.Annotations[key] = value
-			}
-		}
-		if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(newStateful); err != nil {
-			logger.Error(err, "Unable to patch mongodb statefulset with comparison object")
-			return err
-		}
-		return updateStateFulSet(namespace, newStateful)
-	}
-	logger.Info("Reconciliation Complete, no Changes required.")
-	return nil
-}
-
-// createStateFulSet is a method to create statefulset in Kubernetes
-func createStateFulSet(namespace string, stateful *appsv1.StatefulSet) error {
-	logger := logGenerator(stateful.Name, namespace, "StatefulSet")
-	_, err := generateK8sClient().AppsV1().StatefulSets(namespace).Create(context.TODO(), stateful, metav1.CreateOptions{})
-	if err != nil {
-		logger.Error(err, "MongoDB Statefulset creation failed")
+	storedStateful, err := GetStateFulSet(params.Namespace, params.StatefulSetMeta.Name)
+	if err == nil {
+		// volumeClaimTemplates are immutable, changes only apply on new StatefulSets
+		statefulSetDef.Spec.VolumeClaimTemplates = storedStateful.Spec.VolumeClaimTemplates
+	} else if !errors.IsNotFound(err) {
 		return err
 	}
-	logger.Info("MongoDB Statefulset successfully created")
-	return nil
-}
-
-// updateStateFulSet is a method to update statefulset in Kubernetes
-func updateStateFulSet(namespace string, stateful *appsv1.StatefulSet) error {
-	logger := logGenerator(stateful.Name, namespace, "StatefulSet")
-	_, err := generateK8sClient().AppsV1().StatefulSets(namespace).Update(context.TODO(), stateful, metav1.UpdateOptions{})
-	if err != nil {
-		logger.Error(err, "MongoDB Statefulset update failed")
+	return serverSideApply(statefulSetDef, "StatefulSet", func(ctx context.Context, name string, data []byte, options metav1.PatchOptions) error {
+		_, err := generateK8sClient().AppsV1().StatefulSets(params.Namespace).Patch(ctx, name, types.ApplyPatchType, data, options)
 		return err
-	}
-	logger.Info("MongoDB Statefulset successfully updated")
-	return nil
+	})
 }
 
 // GetStateFulSet is a method to get statefulset in Kubernetes
@@ -143,27 +91,42 @@ func generateStatefulSetDef(params statefulSetParameters) *appsv1.StatefulSet {
 			ServiceName: params.StatefulSetMeta.Name,
 			Replicas:    params.Replicas,
 			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{Labels: params.Labels},
+				ObjectMeta: metav1.ObjectMeta{Labels: mergeMaps(params.PodLabels, params.Labels), Annotations: params.PodAnnotations},
 				Spec: corev1.PodSpec{
-					Containers:        generateContainerDef(params.StatefulSetMeta.Name, params.ContainerParams),
-					NodeSelector:      params.NodeSelector,
-					Affinity:          params.Affinity,
-					PriorityClassName: params.PriorityClassName,
-					SecurityContext:   params.SecurityContext,
+					Containers:                append(generateContainerDef(params.StatefulSetMeta.Name, params.ContainerParams), params.Sidecars...),
+					InitContainers:            params.InitContainers,
+					ServiceAccountName:        params.ServiceAccountName,
+					NodeSelector:              params.NodeSelector,
+					Affinity:                  params.Affinity,
+					PriorityClassName:         params.PriorityClassName,
+					SecurityContext:           params.SecurityContext,
+					TopologySpreadConstraints: params.TopologySpreadConstraints,
 				},
 			},
 		},
 	}
 
+	terminationGracePeriod := defaultTerminationGracePeriod
+	if params.TerminationGracePeriod != nil {
+		terminationGracePeriod = *params.TerminationGracePeriod
+	}
+	statefulset.Spec.Template.Spec.TerminationGracePeriodSeconds = &terminationGracePeriod
 	if params.Tolerations != nil {
 		statefulset.Spec.Template.Spec.Tolerations = *params.Tolerations
 	}
 	if params.ContainerParams.PersistenceEnabled != nil && *params.ContainerParams.PersistenceEnabled {
 		statefulset.Spec.VolumeClaimTemplates = append(statefulset.Spec.VolumeClaimTemplates, generatePersistentVolumeTemplate(params.PVCParameters))
 	}
-	if params.AdditionalConfig != nil {
+	if params.AdditionalConfig != nil || params.MongodConfig != nil {
 		statefulset.Spec.Template.Spec.Volumes = getAdditionalConfig(params)
 	}
+	if params.ContainerParams.KeyfileSecret != nil {
+		statefulset.Spec.Template.Spec.Volumes = append(statefulset.Spec.Template.Spec.Volumes, getKeyfileVolumes(*params.ContainerParams.KeyfileSecret)...)
+		statefulset.Spec.Template.Spec.InitContainers = append([]corev1.Container{getKeyfileInitContainer(params.ContainerParams)}, statefulset.Spec.Template.Spec.InitContainers...)
+	}
+	if params.ExtraVolumes != nil {
+		statefulset.Spec.Template.Spec.Volumes = append(statefulset.Spec.Template.Spec.Volumes, *params.ExtraVolumes...)
+	}
 	if params.ImagePullSecret != nil {
 		statefulset.Spec.Template.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: *params.ImagePullSecret}}
 	}
@@ -175,7 +138,7 @@ func generateStatefulSetDef(params statefulSetParameters) *appsv1.StatefulSet {
 func generatePersistentVolumeTemplate(params pvcParameters) corev1.PersistentVolumeClaim {
 	return corev1.PersistentVolumeClaim{
 		TypeMeta:   generateMetaInformation("PersistentVolumeClaim", "v1"),
-		ObjectMeta: metav1.ObjectMeta{Name: params.Name},
+		ObjectMeta: metav1.ObjectMeta{Name: params.Name, Labels: params.Labels, Annotations: params.Annotations},
 		Spec: corev1.PersistentVolumeClaimSpec{
 			AccessModes: params.AccessModes,
 			Resources: corev1.ResourceRequirements{
@@ -190,13 +153,33 @@ func generatePersistentVolumeTemplate(params pvcParameters) corev1.PersistentVol
 
 // getAdditionalConfig will return the MongoDB additional configuration
 func getAdditionalConfig(params statefulSetParameters) []corev1.Volume {
+	if params.AdditionalConfig == nil || params.MongodConfig == nil {
+		configName := params.AdditionalConfig
+		if configName == nil {
+			configName = params.MongodConfig
+		}
+		return []corev1.Volume{
+			{
+				Name: "external-config",
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: *configName,
+						},
+					},
+				},
+			},
+		}
+	}
+	// both the user defined and the generated configuration are projected in the same directory
 	return []corev1.Volume{
 		{
 			Name: "external-config",
 			VolumeSource: corev1.VolumeSource{
-				ConfigMap: &corev1.ConfigMapVolumeSource{
-					LocalObjectReference: corev1.LocalObjectReference{
-						Name: *params.AdditionalConfig,
+				Projected: &corev1.ProjectedVolumeSource{
+					Sources: []corev1.VolumeProjection{
+						{ConfigMap: &corev1.ConfigMapProjection{LocalObjectReference: corev1.LocalObjectReference{Name: *params.AdditionalConfig}}},
+						{ConfigMap: &corev1.ConfigMapProjection{LocalObjectReference: corev1.LocalObjectReference{Name: *params.MongodConfig}}},
 					},
 				},
 			},
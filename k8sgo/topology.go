@@ -0,0 +1,119 @@
+package k8sgo
+
+import (
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"mongodb-operator/mongo"
+)
+
+const (
+	defaultZoneKey = "topology.kubernetes.io/zone"
+	defaultZoneTag = "zone"
+	hostnameKey    = "kubernetes.io/hostname"
+)
+
+// topologySpreadEnabled is a method to check if topology spreading is enabled for MongoDB cluster
+func topologySpreadEnabled(cr *opstreelabsinv1alpha1.MongoDBCluster) bool {
+	return cr.Spec.TopologySpread != nil && cr.Spec.TopologySpread.Enabled
+}
+
+// getZoneKey is a method to get the node label used as zone for MongoDB cluster
+func getZoneKey(cr *opstreelabsinv1alpha1.MongoDBCluster) string {
+	if cr.Spec.TopologySpread.ZoneKey != "" {
+		return cr.Spec.TopologySpread.ZoneKey
+	}
+	return defaultZoneKey
+}
+
+// generateTopologySpreadConstraints is a method to generate topology spread constraints for MongoDB cluster
+func generateTopologySpreadConstraints(cr *opstreelabsinv1alpha1.MongoDBCluster, labels map[string]string) []corev1.TopologySpreadConstraint {
+	maxSkew := int32(1)
+	if cr.Spec.TopologySpread.MaxSkew != nil {
+		maxSkew = *cr.Spec.TopologySpread.MaxSkew
+	}
+	whenUnsatisfiable := corev1.ScheduleAnyway
+	if cr.Spec.TopologySpread.WhenUnsatisfiable != "" {
+		whenUnsatisfiable = cr.Spec.TopologySpread.WhenUnsatisfiable
+	}
+	var constraints []corev1.TopologySpreadConstraint
+	for _, key := range []string{getZoneKey(cr), hostnameKey} {
+		constraints = append(constraints, corev1.TopologySpreadConstraint{
+			MaxSkew:           maxSkew,
+			TopologyKey:       key,
+			WhenUnsatisfiable: whenUnsatisfiable,
+			LabelSelector:     LabelSelectors(labels),
+		})
+	}
+	return constraints
+}
+
+// generatePodAntiAffinity is a method to generate default pod anti-affinity for MongoDB cluster
+func generatePodAntiAffinity(cr *opstreelabsinv1alpha1.MongoDBCluster, labels map[string]string) *corev1.Affinity {
+	return &corev1.Affinity{
+		PodAntiAffinity: &corev1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+				{
+					Weight: 100,
+					PodAffinityTerm: corev1.PodAffinityTerm{
+						LabelSelector: LabelSelectors(labels),
+						TopologyKey:   hostnameKey,
+					},
+				},
+				{
+					Weight: 50,
+					PodAffinityTerm: corev1.PodAffinityTerm{
+						LabelSelector: LabelSelectors(labels),
+						TopologyKey:   getZoneKey(cr),
+					},
+				},
+			},
+		},
+	}
+}
+
+// addMongoZoneTags is a method to add the node zone of each MongoDB cluster member into its replica set tags
+func addMongoZoneTags(cr *opstreelabsinv1alpha1.MongoDBCluster, overrides map[int]mongogo.MemberOverride) {
+	if !topologySpreadEnabled(cr) {
+		return
+	}
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Zone Tags")
+	zoneTag := defaultZoneTag
+	if cr.Spec.TopologySpread.ZoneTag != "" {
+		zoneTag = cr.Spec.TopologySpread.ZoneTag
+	}
+	for node := 0; node < int(*cr.Spec.MongoDBClusterSize); node++ {
+		pod, err := getPod(cr.Namespace, fmt.Sprintf("%s-cluster-%d", cr.ObjectMeta.Name, node))
+		if err != nil || pod.Spec.NodeName == "" {
+			// a restarting member keeps its zone until its pod is scheduled again
+			keepMongoZoneTag(overrides, node, zoneTag)
+			continue
+		}
+		nodeInfo, err := getNode(pod.Spec.NodeName)
+		if err != nil {
+			logger.Error(err, "Unable to get the zone of MongoDB cluster member", "Member", pod.Name)
+			keepMongoZoneTag(overrides, node, zoneTag)
+			continue
+		}
+		zone, ok := nodeInfo.Labels[getZoneKey(cr)]
+		if !ok {
+			keepMongoZoneTag(overrides, node, zoneTag)
+			continue
+		}
+		override := overrides[node]
+		tags := map[string]string{}
+		for key, value := range override.Tags {
+			tags[key] = value
+		}
+		tags[zoneTag] = zone
+		override.Tags = tags
+		overrides[node] = override
+	}
+}
+
+// keepMongoZoneTag is a method to keep the current zone tag of a member whose zone cannot be resolved
+func keepMongoZoneTag(overrides map[int]mongogo.MemberOverride, node int, zoneTag string) {
+	override := overrides[node]
+	override.KeepTags = append(override.KeepTags, zoneTag)
+	overrides[node] = override
+}
@@ -0,0 +1,269 @@
+package k8sgo
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	corev1 "k8s.io/api/core/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// VaultEngineKV is the version 1 key/value secrets engine
+	VaultEngineKV = "kv"
+	// VaultEngineKVv2 is the versioned key/value secrets engine
+	VaultEngineKVv2 = "kv-v2"
+	// VaultEngineDatabase is the database secrets engine, the password of a static role is read and rotated by Vault
+	VaultEngineDatabase = "database"
+
+	defaultVaultAuthMount = "kubernetes"
+	defaultVaultKey       = "password"
+	// vaultAgentSecretsPath is where the Vault Agent injector renders the secrets in the pods
+	vaultAgentSecretsPath = "/vault/secrets"
+)
+
+// errVaultSecretNotFound is returned when Vault does not hold the secret yet
+var errVaultSecretNotFound = errors.New("secret not found in vault")
+
+// serviceAccountTokenPath is the token of the operator service account used to login with the Kubernetes auth method
+var serviceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// vaultTokens caches the tokens of Kubernetes auth logins, indexed by address, mount and role
+var vaultTokens = struct {
+	sync.Mutex
+	tokens map[string]vaultToken
+}{tokens: map[string]vaultToken{}}
+
+// vaultToken is a Vault token with the time it should be renewed
+type vaultToken struct {
+	Token   string
+	RenewAt time.Time
+}
+
+// vaultProvider fetches a password from a kv or database secrets engine of HashiCorp Vault
+type vaultProvider struct {
+	Source *opstreelabsinv1alpha1.VaultCredentialSource
+	// Name is the name of the password file rendered in the pods
+	Name string
+	// Fallback is used while Vault does not hold the secret, and by the pods, only when fallbackToSecret is enabled
+	Fallback CredentialProvider
+}
+
+// GetPassword is a method to get the password from Vault
+func (p *vaultProvider) GetPassword() (string, error) {
+	address := p.Source.Address
+	if address == "" {
+		address = os.Getenv("VAULT_ADDR")
+	}
+	if address == "" {
+		return "", fmt.Errorf("vault address is not set")
+	}
+	address = strings.TrimSuffix(address, "/")
+	client, err := getVaultHTTPClient()
+	if err != nil {
+		return "", err
+	}
+	token, err := p.login(client, address)
+	if err != nil {
+		return "", err
+	}
+	path, key := p.secretPath()
+	data, err := vaultRequest(client, http.MethodGet, fmt.Sprintf("%s/v1/%s", address, path), token, nil)
+	if errors.Is(err, errVaultSecretNotFound) && p.Fallback != nil {
+		return p.Fallback.GetPassword()
+	}
+	if err != nil {
+		return "", err
+	}
+	values, _ := data["data"].(map[string]interface{})
+	if p.engine() == VaultEngineKVv2 {
+		values, _ = values["data"].(map[string]interface{})
+	}
+	password, ok := values[key].(string)
+	if !ok {
+		return "", fmt.Errorf("key %s not found in vault secret %s", key, path)
+	}
+	return password, nil
+}
+
+// GetPodCredential is a method to pass the password to the pods as a file rendered by the Vault Agent injector, the variable holds its path
+func (p *vaultProvider) GetPodCredential(envName string) podCredential {
+	if p.Fallback != nil {
+		return p.Fallback.GetPodCredential(envName)
+	}
+	path, key := p.secretPath()
+	data := ".Data"
+	if p.engine() == VaultEngineKVv2 {
+		data = ".Data.data"
+	}
+	authMount := p.Source.AuthMount
+	if authMount == "" {
+		authMount = defaultVaultAuthMount
+	}
+	annotations := map[string]string{
+		"vault.hashicorp.com/agent-inject":                    "true",
+		"vault.hashicorp.com/role":                            p.podRole(),
+		"vault.hashicorp.com/auth-path":                       fmt.Sprintf("auth/%s", strings.Trim(authMount, "/")),
+		"vault.hashicorp.com/agent-inject-secret-" + p.Name:   path,
+		"vault.hashicorp.com/agent-inject-template-" + p.Name: fmt.Sprintf(`{{- with secret "%s" -}}{{ index %s "%s" }}{{- end -}}`, path, data, key),
+	}
+	if p.Source.Address != "" {
+		annotations["vault.hashicorp.com/service"] = strings.TrimSuffix(p.Source.Address, "/")
+	}
+	return podCredential{
+		Env:         corev1.EnvVar{Name: envName + "_FILE", Value: fmt.Sprintf("%s/%s", vaultAgentSecretsPath, p.Name)},
+		Annotations: annotations,
+	}
+}
+
+// podRole is a method to get the Kubernetes auth role of the pods reading the password
+func (p *vaultProvider) podRole() string {
+	if p.Source.PodRole != "" {
+		return p.Source.PodRole
+	}
+	return p.Source.Role
+}
+
+// engine is a method to get the secrets engine of the password
+func (p *vaultProvider) engine() string {
+	if p.Source.Engine == "" {
+		return VaultEngineKVv2
+	}
+	return p.Source.Engine
+}
+
+// secretPath is a method to get the API path of the secret and the key of the password
+func (p *vaultProvider) secretPath() (string, string) {
+	mount := strings.Trim(p.Source.Mount, "/")
+	path := strings.Trim(p.Source.Path, "/")
+	key := p.Source.Key
+	if key == "" {
+		key = defaultVaultKey
+	}
+	switch p.engine() {
+	case VaultEngineKVv2:
+		return fmt.Sprintf("%s/data/%s", mount, path), key
+	case VaultEngineDatabase:
+		return fmt.Sprintf("%s/static-creds/%s", mount, path), defaultVaultKey
+	default:
+		return fmt.Sprintf("%s/%s", mount, path), key
+	}
+}
+
+// validateVaultCredential is a method to check that the pods can read the admin password kept in Vault, the official and percona entrypoints
+// read it from the file rendered by the Vault Agent injector while the opstree entrypoint only reads it from a variable
+func validateVaultCredential(flavor string, security *opstreelabsinv1alpha1.MongoDBSecurity) error {
+	if !adminPasswordInVault(security) {
+		return nil
+	}
+	if !operatorManagedMongod(flavor) {
+		return fmt.Errorf("the admin password in vault requires the official or percona image flavor, or fallbackToSecret")
+	}
+	if security.Vault.PodRole == "" && security.Vault.Role == "" {
+		return fmt.Errorf("vault podRole or role must be set for the pods to read the admin password")
+	}
+	return nil
+}
+
+// login is a method to get a Vault token, the operator service account logs in with the Kubernetes auth method when a role is set
+func (p *vaultProvider) login(client *http.Client, address string) (string, error) {
+	if p.Source.Role == "" {
+		token := os.Getenv("VAULT_TOKEN")
+		if token == "" {
+			return "", fmt.Errorf("vault role and VAULT_TOKEN are not set")
+		}
+		return token, nil
+	}
+	mount := p.Source.AuthMount
+	if mount == "" {
+		mount = defaultVaultAuthMount
+	}
+	cacheKey := fmt.Sprintf("%s/%s/%s", address, mount, p.Source.Role)
+	vaultTokens.Lock()
+	defer vaultTokens.Unlock()
+	if cached, ok := vaultTokens.tokens[cacheKey]; ok && time.Now().Before(cached.RenewAt) {
+		return cached.Token, nil
+	}
+	jwt, err := ioutil.ReadFile(serviceAccountTokenPath)
+	if err != nil {
+		return "", err
+	}
+	body := map[string]string{"role": p.Source.Role, "jwt": string(jwt)}
+	response, err := vaultRequest(client, http.MethodPost, fmt.Sprintf("%s/v1/auth/%s/login", address, strings.Trim(mount, "/")), "", body)
+	if err != nil {
+		return "", err
+	}
+	auth, _ := response["auth"].(map[string]interface{})
+	token, _ := auth["client_token"].(string)
+	if token == "" {
+		return "", fmt.Errorf("vault login with role %s returned no token", p.Source.Role)
+	}
+	leaseDuration, _ := auth["lease_duration"].(float64)
+	// the token is renewed by a new login once most of its lease is spent
+	vaultTokens.tokens[cacheKey] = vaultToken{Token: token, RenewAt: time.Now().Add(time.Duration(leaseDuration*0.8) * time.Second)}
+	return token, nil
+}
+
+// getVaultHTTPClient is a method to get the HTTP client of Vault, the VAULT_CACERT of the operator is trusted when set
+func getVaultHTTPClient() (*http.Client, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	caCert := os.Getenv("VAULT_CACERT")
+	if caCert == "" {
+		return client, nil
+	}
+	pem, err := ioutil.ReadFile(caCert)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificate found in %s", caCert)
+	}
+	client.Transport = &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}}
+	return client, nil
+}
+
+// vaultRequest is a method to call the Vault HTTP API and decode its JSON response
+func vaultRequest(client *http.Client, method string, url string, token string, body interface{}) (map[string]interface{}, error) {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+	}
+	request, err := http.NewRequest(method, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		request.Header.Set("X-Vault-Token", token)
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusNotFound {
+		return nil, errVaultSecretNotFound
+	}
+	result := map[string]interface{}{}
+	err = json.NewDecoder(response.Body).Decode(&result)
+	if err != nil {
+		return nil, fmt.Errorf("vault returned %s: %v", response.Status, err)
+	}
+	if response.StatusCode >= 300 {
+		return nil, fmt.Errorf("vault returned %s: %v", response.Status, result["errors"])
+	}
+	return result, nil
+}
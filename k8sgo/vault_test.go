@@ -0,0 +1,277 @@
+package k8sgo
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+)
+
+// staticProvider is a provider returning a fixed password
+type staticProvider struct {
+	password string
+}
+
+func (p *staticProvider) GetPassword() (string, error) {
+	return p.password, nil
+}
+
+func (p *staticProvider) GetPodCredential(envName string) podCredential {
+	return podCredential{}
+}
+
+func newVaultServer(t *testing.T, secrets map[string]interface{}) (*httptest.Server, *int) {
+	logins := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/auth/kubernetes/login" {
+			body := map[string]string{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if body["role"] != "mongodb-operator" || body["jwt"] != "service-account-token" {
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+				return
+			}
+			logins++
+			_, _ = w.Write([]byte(`{"auth":{"client_token":"login-token","lease_duration":3600}}`))
+			return
+		}
+		if token := r.Header.Get("X-Vault-Token"); token != "login-token" && token != "env-token" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		secret, ok := secrets[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(secret)
+	}))
+	t.Cleanup(server.Close)
+	return server, &logins
+}
+
+func TestVaultProviderGetPassword(t *testing.T) {
+	server, _ := newVaultServer(t, map[string]interface{}{
+		"/v1/kv/mongodb/admin":            map[string]interface{}{"data": map[string]interface{}{"password": "kv-password", "other": "other-password"}},
+		"/v1/secret/data/mongodb/admin":   map[string]interface{}{"data": map[string]interface{}{"data": map[string]interface{}{"password": "kv-v2-password"}}},
+		"/v1/database/static-creds/admin": map[string]interface{}{"data": map[string]interface{}{"username": "admin", "password": "database-password"}},
+	})
+	t.Setenv("VAULT_TOKEN", "env-token")
+	t.Setenv("VAULT_CACERT", "")
+	tests := []struct {
+		name     string
+		source   opstreelabsinv1alpha1.VaultCredentialSource
+		fallback CredentialProvider
+		want     string
+		wantErr  error
+	}{
+		{name: "kv", source: opstreelabsinv1alpha1.VaultCredentialSource{Engine: VaultEngineKV, Mount: "kv", Path: "mongodb/admin"}, want: "kv-password"},
+		{name: "kv with key", source: opstreelabsinv1alpha1.VaultCredentialSource{Engine: VaultEngineKV, Mount: "kv", Path: "mongodb/admin", Key: "other"}, want: "other-password"},
+		{name: "kv-v2 by default", source: opstreelabsinv1alpha1.VaultCredentialSource{Mount: "secret", Path: "/mongodb/admin/"}, want: "kv-v2-password"},
+		{name: "database", source: opstreelabsinv1alpha1.VaultCredentialSource{Engine: VaultEngineDatabase, Mount: "database", Path: "admin", Key: "ignored"}, want: "database-password"},
+		{name: "missing key", source: opstreelabsinv1alpha1.VaultCredentialSource{Engine: VaultEngineKV, Mount: "kv", Path: "mongodb/admin", Key: "missing"}, wantErr: errors.New("key missing not found in vault secret kv/mongodb/admin")},
+		{name: "not found", source: opstreelabsinv1alpha1.VaultCredentialSource{Mount: "secret", Path: "missing"}, wantErr: errVaultSecretNotFound},
+		{name: "not found with fallback", source: opstreelabsinv1alpha1.VaultCredentialSource{Mount: "secret", Path: "missing"}, fallback: &staticProvider{password: "secret-password"}, want: "secret-password"},
+		{name: "found with fallback", source: opstreelabsinv1alpha1.VaultCredentialSource{Mount: "secret", Path: "mongodb/admin"}, fallback: &staticProvider{password: "secret-password"}, want: "kv-v2-password"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := tt.source
+			source.Address = server.URL + "/"
+			provider := &vaultProvider{Source: &source, Fallback: tt.fallback}
+			got, err := provider.GetPassword()
+			if tt.wantErr != nil {
+				if err == nil || (!errors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error()) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got error %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got password %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVaultProviderLogin(t *testing.T) {
+	tokenPath := filepath.Join(t.TempDir(), "token")
+	if err := ioutil.WriteFile(tokenPath, []byte("service-account-token"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	defaultTokenPath := serviceAccountTokenPath
+	serviceAccountTokenPath = tokenPath
+	t.Cleanup(func() { serviceAccountTokenPath = defaultTokenPath })
+	t.Setenv("VAULT_CACERT", "")
+	tests := []struct {
+		name       string
+		role       string
+		envToken   string
+		address    bool
+		fetches    int
+		wantLogins int
+		wantErr    bool
+	}{
+		{name: "role logs in once", role: "mongodb-operator", address: true, fetches: 2, wantLogins: 1},
+		{name: "VAULT_TOKEN without role", envToken: "env-token", address: true, fetches: 1, wantLogins: 0},
+		{name: "no role and no VAULT_TOKEN", fetches: 1, address: true, wantErr: true},
+		{name: "denied role", role: "unknown", address: true, fetches: 1, wantErr: true},
+		{name: "no address", envToken: "env-token", fetches: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, logins := newVaultServer(t, map[string]interface{}{
+				"/v1/secret/data/mongodb/admin": map[string]interface{}{"data": map[string]interface{}{"data": map[string]interface{}{"password": "kv-v2-password"}}},
+			})
+			t.Setenv("VAULT_TOKEN", tt.envToken)
+			t.Setenv("VAULT_ADDR", "")
+			source := &opstreelabsinv1alpha1.VaultCredentialSource{Role: tt.role, Mount: "secret", Path: "mongodb/admin"}
+			if tt.address {
+				source.Address = server.URL
+			}
+			provider := &vaultProvider{Source: source}
+			for i := 0; i < tt.fetches; i++ {
+				got, err := provider.GetPassword()
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+				}
+				if !tt.wantErr && got != "kv-v2-password" {
+					t.Errorf("got password %q, want kv-v2-password", got)
+				}
+			}
+			if *logins != tt.wantLogins {
+				t.Errorf("got %d logins, want %d", *logins, tt.wantLogins)
+			}
+		})
+	}
+}
+
+func TestVaultProviderGetPodCredential(t *testing.T) {
+	tests := []struct {
+		name            string
+		source          opstreelabsinv1alpha1.VaultCredentialSource
+		fallback        CredentialProvider
+		wantEnv         string
+		wantValue       string
+		wantAnnotations map[string]string
+	}{
+		{
+			name:      "kv-v2",
+			source:    opstreelabsinv1alpha1.VaultCredentialSource{Address: "https://vault.vault.svc:8200/", Role: "mongodb-operator", Mount: "secret", Path: "mongodb/admin"},
+			wantEnv:   "MONGO_INITDB_ROOT_PASSWORD_FILE",
+			wantValue: "/vault/secrets/mongodb-admin",
+			wantAnnotations: map[string]string{
+				"vault.hashicorp.com/agent-inject":                        "true",
+				"vault.hashicorp.com/role":                                "mongodb-operator",
+				"vault.hashicorp.com/auth-path":                           "auth/kubernetes",
+				"vault.hashicorp.com/agent-inject-secret-mongodb-admin":   "secret/data/mongodb/admin",
+				"vault.hashicorp.com/agent-inject-template-mongodb-admin": `{{- with secret "secret/data/mongodb/admin" -}}{{ index .Data.data "password" }}{{- end -}}`,
+				"vault.hashicorp.com/service":                             "https://vault.vault.svc:8200",
+			},
+		},
+		{
+			name:      "database with pod role",
+			source:    opstreelabsinv1alpha1.VaultCredentialSource{Role: "mongodb-operator", PodRole: "mongodb", AuthMount: "/k8s/", Engine: VaultEngineDatabase, Mount: "database", Path: "admin"},
+			wantEnv:   "MONGO_INITDB_ROOT_PASSWORD_FILE",
+			wantValue: "/vault/secrets/mongodb-admin",
+			wantAnnotations: map[string]string{
+				"vault.hashicorp.com/agent-inject":                        "true",
+				"vault.hashicorp.com/role":                                "mongodb",
+				"vault.hashicorp.com/auth-path":                           "auth/k8s",
+				"vault.hashicorp.com/agent-inject-secret-mongodb-admin":   "database/static-creds/admin",
+				"vault.hashicorp.com/agent-inject-template-mongodb-admin": `{{- with secret "database/static-creds/admin" -}}{{ index .Data "password" }}{{- end -}}`,
+			},
+		},
+		{
+			name:      "fallback to secret",
+			source:    opstreelabsinv1alpha1.VaultCredentialSource{Role: "mongodb-operator", Mount: "secret", Path: "mongodb/admin", FallbackToSecret: true},
+			fallback:  &kubernetesSecretProvider{Namespace: "default", SecretName: "mongodb-secret", SecretKey: "password"},
+			wantEnv:   "MONGO_INITDB_ROOT_PASSWORD",
+			wantValue: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &vaultProvider{Source: &tt.source, Name: adminCredentialName, Fallback: tt.fallback}
+			got := provider.GetPodCredential("MONGO_INITDB_ROOT_PASSWORD")
+			if got.Env.Name != tt.wantEnv || got.Env.Value != tt.wantValue {
+				t.Errorf("got env %s=%q, want %s=%q", got.Env.Name, got.Env.Value, tt.wantEnv, tt.wantValue)
+			}
+			if tt.fallback != nil && (got.Env.ValueFrom == nil || got.Env.ValueFrom.SecretKeyRef.Name != "mongodb-secret") {
+				t.Errorf("got env source %v, want the mongodb-secret secret", got.Env.ValueFrom)
+			}
+			if len(got.Annotations) != len(tt.wantAnnotations) {
+				t.Fatalf("got annotations %v, want %v", got.Annotations, tt.wantAnnotations)
+			}
+			for key, value := range tt.wantAnnotations {
+				if got.Annotations[key] != value {
+					t.Errorf("got annotation %s=%q, want %q", key, got.Annotations[key], value)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateVaultCredential(t *testing.T) {
+	tests := []struct {
+		name     string
+		flavor   string
+		security *opstreelabsinv1alpha1.MongoDBSecurity
+		wantErr  bool
+	}{
+		{name: "no security", flavor: ImageFlavorOpstree},
+		{name: "no vault", flavor: ImageFlavorOpstree, security: &opstreelabsinv1alpha1.MongoDBSecurity{}},
+		{name: "official", flavor: ImageFlavorOfficial, security: &opstreelabsinv1alpha1.MongoDBSecurity{Vault: &opstreelabsinv1alpha1.VaultCredentialSource{Role: "mongodb-operator"}}},
+		{name: "percona with pod role", flavor: ImageFlavorPercona, security: &opstreelabsinv1alpha1.MongoDBSecurity{Vault: &opstreelabsinv1alpha1.VaultCredentialSource{PodRole: "mongodb"}}},
+		{name: "opstree", flavor: ImageFlavorOpstree, security: &opstreelabsinv1alpha1.MongoDBSecurity{Vault: &opstreelabsinv1alpha1.VaultCredentialSource{Role: "mongodb-operator"}}, wantErr: true},
+		{name: "default flavor", security: &opstreelabsinv1alpha1.MongoDBSecurity{Vault: &opstreelabsinv1alpha1.VaultCredentialSource{Role: "mongodb-operator"}}, wantErr: true},
+		{name: "opstree with fallback", flavor: ImageFlavorOpstree, security: &opstreelabsinv1alpha1.MongoDBSecurity{Vault: &opstreelabsinv1alpha1.VaultCredentialSource{FallbackToSecret: true}}},
+		{name: "no role", flavor: ImageFlavorOfficial, security: &opstreelabsinv1alpha1.MongoDBSecurity{Vault: &opstreelabsinv1alpha1.VaultCredentialSource{}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateVaultCredential(tt.flavor, tt.security)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetAdminCredentialProvider(t *testing.T) {
+	tests := []struct {
+		name         string
+		security     *opstreelabsinv1alpha1.MongoDBSecurity
+		wantVault    bool
+		wantFallback bool
+		wantInVault  bool
+	}{
+		{name: "secret", security: &opstreelabsinv1alpha1.MongoDBSecurity{}},
+		{name: "vault", security: &opstreelabsinv1alpha1.MongoDBSecurity{Vault: &opstreelabsinv1alpha1.VaultCredentialSource{}}, wantVault: true, wantInVault: true},
+		{name: "vault with fallback", security: &opstreelabsinv1alpha1.MongoDBSecurity{Vault: &opstreelabsinv1alpha1.VaultCredentialSource{FallbackToSecret: true}}, wantVault: true, wantFallback: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := getAdminCredentialProvider("default", tt.security, "mongodb-secret", "password")
+			vault, ok := provider.(*vaultProvider)
+			if ok != tt.wantVault {
+				t.Fatalf("got provider %T, want vault %v", provider, tt.wantVault)
+			}
+			if ok && (vault.Fallback != nil) != tt.wantFallback {
+				t.Errorf("got fallback %v, want fallback %v", vault.Fallback, tt.wantFallback)
+			}
+			if got := adminPasswordInVault(tt.security); got != tt.wantInVault {
+				t.Errorf("got password in vault %v, want %v", got, tt.wantInVault)
+			}
+		})
+	}
+}
@@ -30,9 +30,11 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
 	"mongodb-operator/controllers"
+	"mongodb-operator/metrics"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -46,17 +48,22 @@ func init() {
 
 	utilruntime.Must(opstreelabsinv1alpha1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
+
+	metrics.Register(ctrlmetrics.Registry)
 }
 
 func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var pruneDryRun bool
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.BoolVar(&pruneDryRun, "prune-dry-run", false,
+		"Only log the generated objects which are not desired anymore instead of deleting them.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -79,17 +86,34 @@ func main() {
 	}
 
 	if err = (&controllers.MongoDBReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:      mgr.GetClient(),
+		Scheme:      mgr.GetScheme(),
+		PruneDryRun: pruneDryRun,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "MongoDB")
 		os.Exit(1)
 	}
 	if err = (&controllers.MongoDBClusterReconciler{
+		Client:      mgr.GetClient(),
+		Scheme:      mgr.GetScheme(),
+		Recorder:    mgr.GetEventRecorderFor("mongodbcluster-controller"),
+		PruneDryRun: pruneDryRun,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "MongoDBCluster")
+		os.Exit(1)
+	}
+	if err = (&controllers.MongoDBRestoreReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "MongoDBCluster")
+		setupLog.Error(err, "unable to create controller", "controller", "MongoDBRestore")
+		os.Exit(1)
+	}
+	if err = (&controllers.MongoDBOpsRequestReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "MongoDBOpsRequest")
 		os.Exit(1)
 	}
 	//+kubebuilder:scaffold:builder
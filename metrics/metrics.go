@@ -0,0 +1,177 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metricsNamespace = "mongodb_operator"
+
+// replicaSetStates is the list of member states reported by replSetGetStatus
+var replicaSetStates = []string{
+	"PRIMARY",
+	"SECONDARY",
+	"ARBITER",
+	"STARTUP",
+	"STARTUP2",
+	"RECOVERING",
+	"ROLLBACK",
+	"DOWN",
+	"UNKNOWN",
+	"REMOVED",
+}
+
+var (
+	// ReconcileTotal is the number of reconciliations per custom resource and result
+	ReconcileTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "reconcile_total",
+		Help:      "Total number of reconciliations per MongoDB custom resource and result",
+	}, []string{"controller", "namespace", "name", "result"})
+
+	// StepDurationSeconds is the time spent in each k8sgo reconciliation step
+	StepDurationSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "reconcile_step_duration_seconds",
+		Help:      "Time spent in each reconciliation step of the operator",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"step"})
+
+	// ReplicaSetMembers is the number of replica set members by state
+	ReplicaSetMembers = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "replicaset_members",
+		Help:      "Number of MongoDB replica set members by state",
+	}, []string{"namespace", "name", "state"})
+
+	// PrimaryChangesTotal is the number of primary changes seen by the operator
+	PrimaryChangesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "replicaset_primary_changes_total",
+		Help:      "Total number of MongoDB replica set primary changes observed by the operator",
+	}, []string{"namespace", "name"})
+
+	// MongoConnectionFailuresTotal is the number of failed connections to MongoDB
+	MongoConnectionFailuresTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "mongo_connection_failures_total",
+		Help:      "Total number of failed connections from the operator to MongoDB",
+	}, []string{"namespace", "name"})
+
+	// UserOperationsTotal is the number of user management operations in MongoDB
+	UserOperationsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "user_operations_total",
+		Help:      "Total number of MongoDB user management operations per operation and result",
+	}, []string{"namespace", "name", "operation", "result"})
+)
+
+// results is the list of result label values
+var results = []string{"success", "error"}
+
+// userOperations is the list of user management operations recorded by the operator
+var userOperations = []string{"create", "drop", "get"}
+
+var (
+	primaryLock sync.Mutex
+	primaries   = map[string]string{}
+)
+
+// Collectors returns the list of operator specific collectors
+func Collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		ReconcileTotal,
+		StepDurationSeconds,
+		ReplicaSetMembers,
+		PrimaryChangesTotal,
+		MongoConnectionFailuresTotal,
+		UserOperationsTotal,
+	}
+}
+
+// Register is a method to register operator collectors in the given registry
+func Register(registry prometheus.Registerer) {
+	registry.MustRegister(Collectors()...)
+}
+
+// ObserveReconcile is a method to record the result of a reconciliation
+func ObserveReconcile(controller, namespace, name string, err error) {
+	ReconcileTotal.WithLabelValues(controller, namespace, name, result(err)).Inc()
+}
+
+// StepTimer is a method to measure a reconciliation step, the returned function should be deferred
+func StepTimer(step string) func() {
+	start := time.Now()
+	return func() {
+		StepDurationSeconds.WithLabelValues(step).Observe(time.Since(start).Seconds())
+	}
+}
+
+// ObserveReplicaSetMembers is a method to record replica set members count by state
+func ObserveReplicaSetMembers(namespace, name string, states []string) {
+	count := map[string]float64{}
+	for _, state := range states {
+		count[state]++
+	}
+	for _, state := range replicaSetStates {
+		ReplicaSetMembers.WithLabelValues(namespace, name, state).Set(count[state])
+	}
+}
+
+// ObserveReplicaSetPrimary is a method to record primary changes of a replica set
+func ObserveReplicaSetPrimary(namespace, name, primary string) {
+	if primary == "" {
+		return
+	}
+	key := namespace + "/" + name
+	primaryLock.Lock()
+	defer primaryLock.Unlock()
+	if previous, ok := primaries[key]; ok && previous != primary {
+		PrimaryChangesTotal.WithLabelValues(namespace, name).Inc()
+	}
+	primaries[key] = primary
+}
+
+// ObserveMongoConnectionFailure is a method to record a failed connection to MongoDB
+func ObserveMongoConnectionFailure(namespace, name string) {
+	MongoConnectionFailuresTotal.WithLabelValues(namespace, name).Inc()
+}
+
+// ObserveUserOperation is a method to record a MongoDB user management operation
+func ObserveUserOperation(namespace, name, operation string, err error) {
+	UserOperationsTotal.WithLabelValues(namespace, name, operation, result(err)).Inc()
+}
+
+// DeleteReconcileSeries is a method to remove the reconciliation series of a deleted custom resource
+func DeleteReconcileSeries(controller, namespace, name string) {
+	for _, value := range results {
+		ReconcileTotal.DeleteLabelValues(controller, namespace, name, value)
+	}
+}
+
+// DeleteReplicaSetSeries is a method to remove the MongoDB series of a deleted custom resource
+func DeleteReplicaSetSeries(namespace, name string) {
+	for _, state := range replicaSetStates {
+		ReplicaSetMembers.DeleteLabelValues(namespace, name, state)
+	}
+	PrimaryChangesTotal.DeleteLabelValues(namespace, name)
+	MongoConnectionFailuresTotal.DeleteLabelValues(namespace, name)
+	for _, operation := range userOperations {
+		for _, value := range results {
+			UserOperationsTotal.DeleteLabelValues(namespace, name, operation, value)
+		}
+	}
+	primaryLock.Lock()
+	defer primaryLock.Unlock()
+	delete(primaries, namespace+"/"+name)
+}
+
+// result converts an error into a metric label value
+func result(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "success"
+}
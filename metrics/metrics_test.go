@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestDeleteReplicaSetSeries(t *testing.T) {
+	ObserveReplicaSetMembers("default", "deleted", []string{"PRIMARY", "SECONDARY", "SECONDARY"})
+	ObserveReplicaSetPrimary("default", "deleted", "deleted-cluster-0")
+	ObserveMongoConnectionFailure("default", "deleted")
+	ObserveUserOperation("default", "deleted", "create", nil)
+	ObserveUserOperation("default", "deleted", "drop", errors.New("failed"))
+	ObserveReplicaSetMembers("default", "kept", []string{"PRIMARY"})
+
+	DeleteReplicaSetSeries("default", "deleted")
+
+	tests := []struct {
+		name      string
+		collector prometheus.Collector
+		want      int
+	}{
+		{name: "replica set members of the kept resource", collector: ReplicaSetMembers, want: len(replicaSetStates)},
+		{name: "primary changes", collector: PrimaryChangesTotal, want: 0},
+		{name: "connection failures", collector: MongoConnectionFailuresTotal, want: 0},
+		{name: "user operations", collector: UserOperationsTotal, want: 0},
+	}
+	for _, tt := range tests {
+		if got := testutil.CollectAndCount(tt.collector); got != tt.want {
+			t.Errorf("%s: got %d series, want %d", tt.name, got, tt.want)
+		}
+	}
+	if _, ok := primaries["default/deleted"]; ok {
+		t.Errorf("primary of the deleted resource is still recorded")
+	}
+}
+
+func TestDeleteReconcileSeries(t *testing.T) {
+	ObserveReconcile("MongoDBCluster", "default", "deleted", nil)
+	ObserveReconcile("MongoDBCluster", "default", "deleted", errors.New("failed"))
+	ObserveReconcile("MongoDBCluster", "default", "kept", nil)
+
+	DeleteReconcileSeries("MongoDBCluster", "default", "deleted")
+
+	if got := testutil.CollectAndCount(ReconcileTotal); got != 1 {
+		t.Errorf("got %d reconcile series, want 1", got)
+	}
+	if got := testutil.ToFloat64(ReconcileTotal.WithLabelValues("MongoDBCluster", "default", "kept", "success")); got != 1 {
+		t.Errorf("got %v reconciliations of the kept resource, want 1", got)
+	}
+}
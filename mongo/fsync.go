@@ -0,0 +1,60 @@
+package mongogo
+
+import (
+	"context"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"strings"
+	"time"
+)
+
+// MemberOptime is the last write of a replica set member
+type MemberOptime struct {
+	Timestamp primitive.Timestamp
+	Date      time.Time
+}
+
+// FsyncLockMember is a method to flush and lock the writes of a MongoDB member, it returns the optime of the locked data
+func FsyncLockMember(params MongoDBParameters) (*MemberOptime, error) {
+	client := initiateMongoClient(params)
+	defer discconnectMongoClient(client)
+	response := client.Database(dbName).RunCommand(context.Background(), bson.D{{Key: "fsync", Value: 1}, {Key: "lock", Value: true}})
+	observeCommandError(params, response.Err())
+	if response.Err() != nil {
+		return nil, response.Err()
+	}
+	var hello struct {
+		LastWrite struct {
+			OpTime struct {
+				TS primitive.Timestamp `bson:"ts"`
+			} `bson:"opTime"`
+			LastWriteDate time.Time `bson:"lastWriteDate"`
+		} `bson:"lastWrite"`
+	}
+	err := client.Database(dbName).RunCommand(context.Background(), bson.D{{Key: "isMaster", Value: 1}}).Decode(&hello)
+	observeCommandError(params, err)
+	if err != nil {
+		// the member is locked already, it must not stay locked without a snapshot
+		unlockErr := client.Database(dbName).RunCommand(context.Background(), bson.D{{Key: "fsyncUnlock", Value: 1}}).Err()
+		observeCommandError(params, unlockErr)
+		if unlockErr != nil {
+			return nil, fmt.Errorf("%v, and the member could not be unlocked: %v", err, unlockErr)
+		}
+		return nil, err
+	}
+	return &MemberOptime{Timestamp: hello.LastWrite.OpTime.TS, Date: hello.LastWrite.LastWriteDate}, nil
+}
+
+// FsyncUnlockMember is a method to unlock the writes of a MongoDB member locked with FsyncLockMember, a member which is not locked is not an error
+func FsyncUnlockMember(params MongoDBParameters) error {
+	client := initiateMongoClient(params)
+	defer discconnectMongoClient(client)
+	err := client.Database(dbName).RunCommand(context.Background(), bson.D{{Key: "fsyncUnlock", Value: 1}}).Err()
+	// a restarted member is not locked anymore
+	if err != nil && strings.Contains(err.Error(), "not locked") {
+		return nil
+	}
+	observeCommandError(params, err)
+	return err
+}
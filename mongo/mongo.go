@@ -2,11 +2,13 @@ package mongogo
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/go-logr/logr"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"mongodb-operator/metrics"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"time"
 )
@@ -16,17 +18,44 @@ var log = logf.Log.WithName("controller_mongo")
 const (
 	dbName         = "admin"
 	monitoringUser = "monitoring"
+	backupUser     = "backup"
 )
 
 // MongoDBParameters is a struct for MongoDB related inputs
 type MongoDBParameters struct {
-	MongoURL     string
-	SetupType    string
-	Namespace    string
-	Name         string
-	Password     string
-	UserName     *string
-	ClusterNodes *int32
+	MongoURL        string
+	SetupType       string
+	Namespace       string
+	Name            string
+	Password        string
+	UserName        *string
+	ClusterNodes    *int32
+	MemberOverrides map[int]MemberOverride
+	ExternalMembers []ExternalMember
+	// Domain replaces the service domain of the member hosts in the replica set configuration
+	Domain string
+}
+
+// ReplicaSetMember is the status of a replica set member reported by replSetGetStatus
+type ReplicaSetMember struct {
+	ID                   int       `bson:"_id"`
+	Name                 string    `bson:"name"`
+	Health               float64   `bson:"health"`
+	State                int       `bson:"state"`
+	StateStr             string    `bson:"stateStr"`
+	OptimeDate           time.Time `bson:"optimeDate"`
+	InfoMessage          string    `bson:"infoMessage,omitempty"`
+	LastHeartbeatMessage string    `bson:"lastHeartbeatMessage,omitempty"`
+	Self                 bool      `bson:"self,omitempty"`
+	// Hidden and Votes are read from the replica set configuration, replSetGetStatus does not report them
+	Hidden bool `bson:"-"`
+	Votes  int  `bson:"-"`
+}
+
+// ReplicaSetStatus is the output of replSetGetStatus command
+type ReplicaSetStatus struct {
+	Set     string             `bson:"set"`
+	Members []ReplicaSetMember `bson:"members"`
 }
 
 // initiateMongoClient is a method to create client connection with MongoDB
@@ -37,6 +66,7 @@ func initiateMongoClient(params MongoDBParameters) *mongo.Client {
 	client, err := mongo.Connect(ctx, options.Client().ApplyURI(params.MongoURL).SetDirect(true))
 	if err != nil {
 		logger.Error(err, "Unable to establish connection with MongoDB")
+		metrics.ObserveMongoConnectionFailure(params.Namespace, params.Name)
 	}
 	return client
 }
@@ -49,6 +79,7 @@ func initiateMongoClusterClient(params MongoDBParameters) *mongo.Client {
 	client, err := mongo.Connect(ctx, options.Client().ApplyURI(params.MongoURL))
 	if err != nil {
 		logger.Error(err, "Unable to establish connection with MongoDB Cluster")
+		metrics.ObserveMongoConnectionFailure(params.Namespace, params.Name)
 	}
 	return client
 }
@@ -76,6 +107,8 @@ func CreateMonitoringUser(params MongoDBParameters) error {
 		{"createUser", monitoringUser}, {"pwd", params.Password},
 		{"roles", []bson.M{{"role": "clusterMonitor", "db": "admin"}, {"role": "read", "db": "local"}}}},
 	)
+	observeCommandError(params, response.Err())
+	metrics.ObserveUserOperation(params.Namespace, params.Name, "create", response.Err())
 	if response.Err() != nil {
 		return response.Err()
 	}
@@ -86,6 +119,38 @@ func CreateMonitoringUser(params MongoDBParameters) error {
 	return nil
 }
 
+// CreateBackupUser is a method to create the user used by backup and oplog archiving inside MongoDB
+func CreateBackupUser(params MongoDBParameters) error {
+	client := initiateMongoClusterClient(params)
+	response := client.Database(dbName).RunCommand(context.Background(), bson.D{
+		{Key: "createUser", Value: backupUser}, {Key: "pwd", Value: params.Password},
+		{Key: "roles", Value: []bson.M{{"role": "backup", "db": "admin"}, {"role": "clusterMonitor", "db": "admin"}, {"role": "read", "db": "local"}}}},
+	)
+	observeCommandError(params, response.Err())
+	metrics.ObserveUserOperation(params.Namespace, params.Name, "create", response.Err())
+	if response.Err() != nil {
+		return response.Err()
+	}
+	return discconnectMongoClient(client)
+}
+
+// DropMongoDBUser is a method to delete a user inside MongoDB
+func DropMongoDBUser(params MongoDBParameters) error {
+	var client *mongo.Client
+	if params.SetupType == "cluster" {
+		client = initiateMongoClusterClient(params)
+	} else {
+		client = initiateMongoClient(params)
+	}
+	response := client.Database(dbName).RunCommand(context.Background(), bson.D{{Key: "dropUser", Value: *params.UserName}})
+	observeCommandError(params, response.Err())
+	metrics.ObserveUserOperation(params.Namespace, params.Name, "drop", response.Err())
+	if response.Err() != nil {
+		return response.Err()
+	}
+	return discconnectMongoClient(client)
+}
+
 //nolint:govet
 // GetMongoDBUser is a method to check if user exists in MongoDB
 func GetMongoDBUser(params MongoDBParameters) (bool, error) {
@@ -100,6 +165,8 @@ func GetMongoDBUser(params MongoDBParameters) (bool, error) {
 	defer cancel()
 	opts := options.Count().SetMaxTime(2 * time.Second)
 	docsCount, err := collection.CountDocuments(ctx, bson.D{{"user", *params.UserName}}, opts)
+	observeCommandError(params, err)
+	metrics.ObserveUserOperation(params.Namespace, params.Name, "get", err)
 	if err != nil {
 		return false, err
 	}
@@ -118,13 +185,14 @@ func InitiateMongoClusterRS(params MongoDBParameters) error {
 	var mongoNodeInfo []bson.M
 	client := initiateMongoClient(params)
 	for node := 0; node < int(*params.ClusterNodes); node++ {
-		mongoNodeInfo = append(mongoNodeInfo, bson.M{"_id": node, "host": GetMongoNodeInfo(params, node)})
+		mongoNodeInfo = append(mongoNodeInfo, generateMemberConfig(params, node))
 	}
 	config := bson.M{
 		"_id":     params.Name,
 		"members": mongoNodeInfo,
 	}
 	response := client.Database(dbName).RunCommand(context.Background(), bson.M{"replSetInitiate": config})
+	observeCommandError(params, response.Err())
 	if response.Err() != nil {
 		return response.Err()
 	}
@@ -140,6 +208,7 @@ func CheckMongoClusterInitialized(params MongoDBParameters) (bool, error) {
 	client := initiateMongoClient(params)
 	var result bson.M
 	err := client.Database(dbName).RunCommand(context.Background(), bson.D{{Key: "replSetGetStatus", Value: 1}}).Decode(&result)
+	observeCommandError(params, err)
 	if err != nil {
 		return false, err
 	}
@@ -149,11 +218,73 @@ func CheckMongoClusterInitialized(params MongoDBParameters) (bool, error) {
 	return false, nil
 }
 
+// GetReplicaSetStatus is a method to get the replica set status of MongoDB cluster
+func GetReplicaSetStatus(params MongoDBParameters) (*ReplicaSetStatus, error) {
+	client := initiateMongoClusterClient(params)
+	defer discconnectMongoClient(client)
+	var status ReplicaSetStatus
+	err := client.Database(dbName).RunCommand(context.Background(), bson.D{{Key: "replSetGetStatus", Value: 1}}).Decode(&status)
+	observeCommandError(params, err)
+	if err != nil {
+		return nil, err
+	}
+	var config struct {
+		Config replicaSetConfig `bson:"config"`
+	}
+	err = client.Database(dbName).RunCommand(context.Background(), bson.D{{Key: "replSetGetConfig", Value: 1}}).Decode(&config)
+	observeCommandError(params, err)
+	if err != nil {
+		return nil, err
+	}
+	for index, member := range status.Members {
+		for _, configMember := range config.Config.Members {
+			if configMember.ID == member.ID {
+				status.Members[index].Hidden = configMember.Hidden
+				status.Members[index].Votes = configMember.Votes
+			}
+		}
+	}
+	return &status, nil
+}
+
 // GetMongoNodeInfo is a method to get info for MongoDB node
 func GetMongoNodeInfo(params MongoDBParameters, count int) string {
+	if params.Domain != "" {
+		return fmt.Sprintf("%s-cluster-%v.%s:27017", params.Name, count, params.Domain)
+	}
 	return fmt.Sprintf("%s-cluster-%v.%s-cluster.%s:27017", params.Name, count, params.Name, params.Namespace)
 }
 
+// observeCommandError is a method to record the connection failures returned by MongoDB commands
+func observeCommandError(params MongoDBParameters, err error) {
+	if err != nil && (mongo.IsNetworkError(err) || mongo.IsTimeout(err)) {
+		metrics.ObserveMongoConnectionFailure(params.Namespace, params.Name)
+	}
+}
+
+// transientCommandErrors are the codes of the command errors returned while a member is unreachable, shutting down or losing its primary state
+var transientCommandErrors = map[int32]bool{
+	6:     true, // HostUnreachable
+	7:     true, // HostNotFound
+	89:    true, // NetworkTimeout
+	91:    true, // ShutdownInProgress
+	189:   true, // PrimarySteppedDown
+	10107: true, // NotWritablePrimary
+	11600: true, // InterruptedAtShutdown
+	11602: true, // InterruptedDueToReplStateChange
+	13435: true, // NotPrimaryNoSecondaryOk
+	13436: true, // NotPrimaryOrSecondary
+}
+
+// IsCommandError is a method to check if MongoDB refused a command, unlike connection errors and elections it fails the same way when retried
+func IsCommandError(err error) bool {
+	var commandErr mongo.CommandError
+	if !errors.As(err, &commandErr) || mongo.IsNetworkError(err) || mongo.IsTimeout(err) {
+		return false
+	}
+	return !transientCommandErrors[commandErr.Code]
+}
+
 // logGenerator is a method to generate logging interface
 func logGenerator(name, namespace, resourceType string) logr.Logger {
 	reqLogger := log.WithValues("Namespace", namespace, "Name", name, "Resource Type", resourceType)
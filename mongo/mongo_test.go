@@ -0,0 +1,34 @@
+package mongogo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestIsCommandError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "no error", err: nil, want: false},
+		{name: "missing collection", err: mongo.CommandError{Code: 26, Name: "NamespaceNotFound"}, want: true},
+		{name: "wrapped command error", err: fmt.Errorf("compact: %w", mongo.CommandError{Code: 13, Name: "Unauthorized"}), want: true},
+		{name: "primary stepped down", err: mongo.CommandError{Code: 189, Name: "PrimarySteppedDown"}, want: false},
+		{name: "not writable primary", err: mongo.CommandError{Code: 10107, Name: "NotWritablePrimary"}, want: false},
+		{name: "network error", err: mongo.CommandError{Code: 26, Labels: []string{"NetworkError"}}, want: false},
+		{name: "timeout", err: context.DeadlineExceeded, want: false},
+		{name: "other error", err: errors.New("server selection error"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsCommandError(tt.err); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
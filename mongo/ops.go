@@ -0,0 +1,24 @@
+package mongogo
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// CompactCollection is a method to rewrite and defragment a collection on a single MongoDB member
+func CompactCollection(params MongoDBParameters, database string, collection string) error {
+	client := initiateMongoClient(params)
+	defer discconnectMongoClient(client)
+	response := client.Database(database).RunCommand(context.Background(), bson.D{{Key: "compact", Value: collection}})
+	observeCommandError(params, response.Err())
+	return response.Err()
+}
+
+// RotateLogs is a method to rotate the log file of a single MongoDB member
+func RotateLogs(params MongoDBParameters) error {
+	client := initiateMongoClient(params)
+	defer discconnectMongoClient(client)
+	response := client.Database(dbName).RunCommand(context.Background(), bson.D{{Key: "logRotate", Value: 1}})
+	observeCommandError(params, response.Err())
+	return response.Err()
+}
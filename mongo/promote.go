@@ -0,0 +1,77 @@
+package mongogo
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// GetMemberReplicaSetStatus is a method to get the replica set status seen by a single MongoDB node, it works without a primary
+func GetMemberReplicaSetStatus(params MongoDBParameters) (*ReplicaSetStatus, error) {
+	client := initiateMongoClient(params)
+	defer discconnectMongoClient(client)
+	var status ReplicaSetStatus
+	err := client.Database(dbName).RunCommand(context.Background(), bson.D{{Key: "replSetGetStatus", Value: 1}}).Decode(&status)
+	observeCommandError(params, err)
+	if err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// PromoteMongoClusterRS is a method to force reconfigure the surviving members into a new replica set majority, it returns the removed members
+func PromoteMongoClusterRS(params MongoDBParameters) ([]string, error) {
+	logger := logGenerator(params.Name, params.Namespace, "MongoDB Cluster Promotion")
+	status, err := GetMemberReplicaSetStatus(params)
+	if err != nil {
+		return nil, err
+	}
+	client := initiateMongoClient(params)
+	defer discconnectMongoClient(client)
+	var result struct {
+		Config replicaSetConfig `bson:"config"`
+	}
+	err = client.Database(dbName).RunCommand(context.Background(), bson.D{{Key: "replSetGetConfig", Value: 1}}).Decode(&result)
+	observeCommandError(params, err)
+	if err != nil {
+		return nil, err
+	}
+	healthy := map[string]bool{}
+	for _, member := range status.Members {
+		if member.Health == 1 {
+			healthy[member.Name] = true
+		}
+	}
+	config := &result.Config
+	externalMembers := getExternalMembers(params)
+	var members []replicaSetConfigMember
+	var removed []string
+	voters := 0
+	for _, member := range config.Members {
+		if !healthy[member.Host] {
+			removed = append(removed, member.Host)
+			continue
+		}
+		if external, ok := externalMembers[member.Host]; ok {
+			applyExternalMember(&member, external)
+		} else if isLocalMember(params, member.Host) && !member.ArbiterOnly {
+			for node := 0; node < int(*params.ClusterNodes); node++ {
+				if member.Host == GetMongoNodeInfo(params, node) {
+					applyMemberOverride(&member, params.MemberOverrides[node])
+					break
+				}
+			}
+		}
+		if member.Votes > 0 {
+			voters++
+		}
+		members = append(members, member)
+	}
+	if voters == 0 {
+		return nil, fmt.Errorf("no surviving voting member, cannot promote the replica set")
+	}
+	config.Members = members
+	logger.Info("Force reconfiguring the surviving members into a new majority", "Removed", removed)
+	return removed, reconfigReplicaSet(params, config, true)
+}
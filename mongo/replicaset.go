@@ -0,0 +1,322 @@
+package mongogo
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MemberOverride is the replica set configuration override for a MongoDB node
+type MemberOverride struct {
+	Priority           *int32
+	Votes              *int32
+	Hidden             bool
+	SecondaryDelaySecs *int32
+	Tags               map[string]string
+	// KeepTags are the tags kept from the current member configuration when they are not set, like a zone which cannot be resolved
+	KeepTags []string
+}
+
+// ExternalMember is a MongoDB node running outside of the Kubernetes cluster, added without votes and priority
+type ExternalMember struct {
+	Host string
+	Tags map[string]string
+}
+
+// externalMemberTag is the replica set tag of the external members added by the operator, tag names cannot contain dots
+const externalMemberTag = "mongodb-operator-external"
+
+// replicaSetConfig is the replica set configuration document
+type replicaSetConfig struct {
+	ID      string                   `bson:"_id"`
+	Version int                      `bson:"version"`
+	Members []replicaSetConfigMember `bson:"members"`
+	Extra   bson.M                   `bson:",inline"`
+}
+
+// replicaSetConfigMember is the member document of replica set configuration
+type replicaSetConfigMember struct {
+	ID                 int               `bson:"_id"`
+	Host               string            `bson:"host"`
+	ArbiterOnly        bool              `bson:"arbiterOnly"`
+	Priority           float64           `bson:"priority"`
+	Votes              int               `bson:"votes"`
+	Hidden             bool              `bson:"hidden"`
+	SecondaryDelaySecs int               `bson:"secondaryDelaySecs"`
+	Tags               map[string]string `bson:"tags"`
+	Extra              bson.M            `bson:",inline"`
+}
+
+// generateMemberConfig is a method to generate the replica set member document for a MongoDB node
+func generateMemberConfig(params MongoDBParameters, node int) bson.M {
+	member := replicaSetConfigMember{ID: node, Host: GetMongoNodeInfo(params, node)}
+	applyMemberOverride(&member, params.MemberOverrides[node])
+	memberInfo := bson.M{"_id": member.ID, "host": member.Host}
+	if member.Priority != 1 {
+		memberInfo["priority"] = member.Priority
+	}
+	if member.Votes != 1 {
+		memberInfo["votes"] = member.Votes
+	}
+	if member.Hidden {
+		memberInfo["hidden"] = member.Hidden
+	}
+	if member.SecondaryDelaySecs != 0 {
+		memberInfo["secondaryDelaySecs"] = member.SecondaryDelaySecs
+	}
+	if len(member.Tags) > 0 {
+		memberInfo["tags"] = member.Tags
+	}
+	return memberInfo
+}
+
+// applyMemberOverride is a method to apply the override on a replica set member, members without override are reset to defaults
+func applyMemberOverride(member *replicaSetConfigMember, override MemberOverride) {
+	member.Priority = 1
+	member.Votes = 1
+	member.Hidden = override.Hidden
+	member.SecondaryDelaySecs = 0
+	member.Tags = mergeKeptTags(override.Tags, member.Tags, override.KeepTags)
+	if override.Priority != nil {
+		member.Priority = float64(*override.Priority)
+	}
+	if override.Votes != nil {
+		member.Votes = int(*override.Votes)
+	}
+	if override.SecondaryDelaySecs != nil {
+		member.SecondaryDelaySecs = int(*override.SecondaryDelaySecs)
+	}
+	// hidden, delayed and non-voting members can never become primary
+	if member.Hidden || member.SecondaryDelaySecs > 0 || member.Votes == 0 {
+		member.Priority = 0
+	}
+}
+
+// mergeKeptTags is a method to add the kept tags of the current configuration to the desired tags
+func mergeKeptTags(desired map[string]string, current map[string]string, keep []string) map[string]string {
+	kept := map[string]string{}
+	for _, key := range keep {
+		if _, set := desired[key]; set {
+			continue
+		}
+		if value, ok := current[key]; ok {
+			kept[key] = value
+		}
+	}
+	if len(kept) == 0 {
+		return desired
+	}
+	// the desired tags are shared with the other reconfigs, so the kept tags are added on a copy
+	for key, value := range desired {
+		kept[key] = value
+	}
+	return kept
+}
+
+// getReplicaSetConfig is a method to get the replica set configuration of MongoDB cluster
+func getReplicaSetConfig(params MongoDBParameters) (*replicaSetConfig, error) {
+	client := initiateMongoClusterClient(params)
+	defer discconnectMongoClient(client)
+	var result struct {
+		Config replicaSetConfig `bson:"config"`
+	}
+	err := client.Database(dbName).RunCommand(context.Background(), bson.D{{Key: "replSetGetConfig", Value: 1}}).Decode(&result)
+	observeCommandError(params, err)
+	if err != nil {
+		return nil, err
+	}
+	return &result.Config, nil
+}
+
+// reconfigReplicaSet is a method to apply a new replica set configuration on MongoDB cluster
+func reconfigReplicaSet(params MongoDBParameters, config *replicaSetConfig, force bool) error {
+	var client *mongo.Client
+	if force {
+		// a forced reconfig is accepted by any member, there may be no primary to route it to
+		client = initiateMongoClient(params)
+	} else {
+		client = initiateMongoClusterClient(params)
+	}
+	defer discconnectMongoClient(client)
+	// term is managed by the server and cannot be part of a reconfig
+	delete(config.Extra, "term")
+	config.Version++
+	response := client.Database(dbName).RunCommand(context.Background(), bson.D{{Key: "replSetReconfig", Value: config}, {Key: "force", Value: force}})
+	observeCommandError(params, response.Err())
+	return response.Err()
+}
+
+// ReconfigMongoClusterRS is a method to apply the member overrides on an initialized MongoDB cluster, it returns if the configuration changed
+func ReconfigMongoClusterRS(params MongoDBParameters) (bool, error) {
+	logger := logGenerator(params.Name, params.Namespace, "MongoDB Cluster Reconfig")
+	config, err := getReplicaSetConfig(params)
+	if err != nil {
+		return false, err
+	}
+	if !updateReplicaSetConfig(params, config) {
+		return false, nil
+	}
+	logger.Info("Replica set member configuration changed, reconfiguring the cluster")
+	return true, reconfigReplicaSet(params, config, false)
+}
+
+// updateReplicaSetConfig is a method to apply the desired members on the replica set configuration, it returns if the configuration changed
+func updateReplicaSetConfig(params MongoDBParameters, config *replicaSetConfig) bool {
+	logger := logGenerator(params.Name, params.Namespace, "MongoDB Cluster Reconfig")
+	changed := false
+	// a non forced reconfig can add, remove or change the votes of only one voting member, the other changes wait for the next reconfig
+	votingChanged := false
+	externalMembers := getExternalMembers(params)
+	var members []replicaSetConfigMember
+	for _, member := range config.Members {
+		if member.ArbiterOnly {
+			members = append(members, member)
+			continue
+		}
+		desired := member
+		if external, ok := externalMembers[member.Host]; ok {
+			applyExternalMember(&desired, external)
+		} else if isLocalMember(params, member.Host) {
+			for node := 0; node < int(*params.ClusterNodes); node++ {
+				if member.Host == GetMongoNodeInfo(params, node) {
+					applyMemberOverride(&desired, params.MemberOverrides[node])
+					break
+				}
+			}
+		} else if member.Tags[externalMemberTag] != "" {
+			if member.Votes > 0 {
+				if votingChanged {
+					members = append(members, member)
+					continue
+				}
+				votingChanged = true
+			}
+			logger.Info("Removing an external member which is not declared anymore", "Host", member.Host)
+			changed = true
+			continue
+		} else {
+			// members added outside of the operator, or local members of a previous domain, are never removed
+			logger.Info("Keeping a replica set member which is not managed by the operator", "Host", member.Host)
+			members = append(members, member)
+			continue
+		}
+		if len(desired.Tags) == 0 && len(member.Tags) == 0 {
+			desired.Tags = member.Tags
+		}
+		if desired.Votes != member.Votes {
+			if votingChanged {
+				desired = member
+			}
+			votingChanged = true
+		}
+		if !reflect.DeepEqual(desired, member) {
+			changed = true
+		}
+		members = append(members, desired)
+	}
+	config.Members = members
+	if member, ok := getMissingMember(params, config); ok && (member.Votes == 0 || !votingChanged) {
+		logger.Info("Adding a new member in the replica set", "Host", member.Host)
+		config.Members = append(config.Members, member)
+		changed = true
+	}
+	// external members have no votes, so all of them can be added by the same reconfig
+	for _, member := range getMissingExternalMembers(params, config) {
+		logger.Info("Adding an external member in the replica set", "Host", member.Host)
+		config.Members = append(config.Members, member)
+		changed = true
+	}
+	return changed
+}
+
+// getMissingMember is a method to get the first MongoDB node which is not a member of the replica set yet
+func getMissingMember(params MongoDBParameters, config *replicaSetConfig) (replicaSetConfigMember, bool) {
+	maxID := getMaxMemberID(config)
+	hosts := map[string]bool{}
+	for _, member := range config.Members {
+		hosts[member.Host] = true
+	}
+	for node := 0; node < int(*params.ClusterNodes); node++ {
+		host := GetMongoNodeInfo(params, node)
+		if hosts[host] {
+			continue
+		}
+		member := replicaSetConfigMember{ID: maxID + 1, Host: host}
+		applyMemberOverride(&member, params.MemberOverrides[node])
+		if member.Tags == nil {
+			member.Tags = map[string]string{}
+		}
+		return member, true
+	}
+	return replicaSetConfigMember{}, false
+}
+
+// getMaxMemberID is a method to get the highest member id of the replica set configuration
+func getMaxMemberID(config *replicaSetConfig) int {
+	maxID := 0
+	for _, member := range config.Members {
+		if member.ID > maxID {
+			maxID = member.ID
+		}
+	}
+	return maxID
+}
+
+// getExternalMembers is a method to index the external members by host
+func getExternalMembers(params MongoDBParameters) map[string]ExternalMember {
+	members := map[string]ExternalMember{}
+	for _, member := range params.ExternalMembers {
+		members[member.Host] = member
+	}
+	return members
+}
+
+// getMissingExternalMembers is a method to get the external members which are not members of the replica set yet
+func getMissingExternalMembers(params MongoDBParameters, config *replicaSetConfig) []replicaSetConfigMember {
+	maxID := getMaxMemberID(config)
+	hosts := map[string]bool{}
+	for _, member := range config.Members {
+		hosts[member.Host] = true
+	}
+	var missing []replicaSetConfigMember
+	for _, external := range params.ExternalMembers {
+		if hosts[external.Host] {
+			continue
+		}
+		maxID++
+		member := replicaSetConfigMember{ID: maxID, Host: external.Host}
+		applyExternalMember(&member, external)
+		if member.Tags == nil {
+			member.Tags = map[string]string{}
+		}
+		missing = append(missing, member)
+	}
+	return missing
+}
+
+// applyExternalMember is a method to configure a replica set member running outside of the Kubernetes cluster
+func applyExternalMember(member *replicaSetConfigMember, external ExternalMember) {
+	// external members replicate the data but never take part in elections
+	member.Priority = 0
+	member.Votes = 0
+	member.Hidden = false
+	member.SecondaryDelaySecs = 0
+	// the tag records the members added by the operator, only they are removed when they are not declared anymore
+	member.Tags = map[string]string{externalMemberTag: "true"}
+	for key, value := range external.Tags {
+		member.Tags[key] = value
+	}
+}
+
+// isLocalMember is a method to check if a replica set member is a MongoDB node of this cluster
+func isLocalMember(params MongoDBParameters, host string) bool {
+	domain := fmt.Sprintf("%s-cluster.%s", params.Name, params.Namespace)
+	if params.Domain != "" {
+		domain = params.Domain
+	}
+	return strings.HasPrefix(host, fmt.Sprintf("%s-cluster-", params.Name)) && strings.HasSuffix(host, fmt.Sprintf(".%s:27017", domain))
+}
@@ -0,0 +1,202 @@
+package mongogo
+
+import (
+	"strings"
+	"testing"
+)
+
+func int32Ptr(value int32) *int32 {
+	return &value
+}
+
+func TestApplyMemberOverride(t *testing.T) {
+	tests := []struct {
+		name         string
+		override     MemberOverride
+		wantPriority float64
+		wantVotes    int
+		wantDelay    int
+	}{
+		{name: "defaults", override: MemberOverride{}, wantPriority: 1, wantVotes: 1},
+		{name: "priority", override: MemberOverride{Priority: int32Ptr(5)}, wantPriority: 5, wantVotes: 1},
+		{name: "hidden", override: MemberOverride{Priority: int32Ptr(5), Hidden: true}, wantPriority: 0, wantVotes: 1},
+		{name: "delayed", override: MemberOverride{SecondaryDelaySecs: int32Ptr(3600)}, wantPriority: 0, wantVotes: 1, wantDelay: 3600},
+		{name: "non-voting", override: MemberOverride{Votes: int32Ptr(0)}, wantPriority: 0, wantVotes: 0},
+		{name: "non-voting with priority", override: MemberOverride{Votes: int32Ptr(0), Priority: int32Ptr(2)}, wantPriority: 0, wantVotes: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			member := replicaSetConfigMember{Priority: 3, Votes: 0, SecondaryDelaySecs: 10}
+			applyMemberOverride(&member, tt.override)
+			if member.Priority != tt.wantPriority || member.Votes != tt.wantVotes || member.SecondaryDelaySecs != tt.wantDelay {
+				t.Errorf("got priority %v, votes %d, delay %d, want priority %v, votes %d, delay %d",
+					member.Priority, member.Votes, member.SecondaryDelaySecs, tt.wantPriority, tt.wantVotes, tt.wantDelay)
+			}
+		})
+	}
+}
+
+func TestUpdateReplicaSetConfig(t *testing.T) {
+	three := int32(3)
+	params := MongoDBParameters{Name: "mongodb", Namespace: "default", ClusterNodes: &three}
+	member := func(node int, votes int) replicaSetConfigMember {
+		priority := float64(votes)
+		return replicaSetConfigMember{ID: node, Host: GetMongoNodeInfo(params, node), Priority: priority, Votes: votes, Tags: map[string]string{}}
+	}
+	tests := []struct {
+		name        string
+		overrides   map[int]MemberOverride
+		members     []replicaSetConfigMember
+		wantChanged bool
+		wantVotes   []int
+	}{
+		{
+			name:        "unchanged",
+			members:     []replicaSetConfigMember{member(0, 1), member(1, 1), member(2, 1)},
+			wantChanged: false,
+			wantVotes:   []int{1, 1, 1},
+		},
+		{
+			name:        "one voting change",
+			overrides:   map[int]MemberOverride{2: {Votes: int32Ptr(0)}},
+			members:     []replicaSetConfigMember{member(0, 1), member(1, 1), member(2, 1)},
+			wantChanged: true,
+			wantVotes:   []int{1, 1, 0},
+		},
+		{
+			name:        "voting changes are applied one at a time",
+			overrides:   map[int]MemberOverride{1: {Votes: int32Ptr(0)}, 2: {Votes: int32Ptr(0)}},
+			members:     []replicaSetConfigMember{member(0, 1), member(1, 1), member(2, 1)},
+			wantChanged: true,
+			wantVotes:   []int{1, 0, 1},
+		},
+		{
+			name:        "missing voting member waits for a voting change",
+			overrides:   map[int]MemberOverride{1: {Votes: int32Ptr(0)}},
+			members:     []replicaSetConfigMember{member(0, 1), member(1, 1)},
+			wantChanged: true,
+			wantVotes:   []int{1, 0},
+		},
+		{
+			name:        "missing voting member",
+			members:     []replicaSetConfigMember{member(0, 1), member(1, 1)},
+			wantChanged: true,
+			wantVotes:   []int{1, 1, 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testParams := params
+			testParams.MemberOverrides = tt.overrides
+			config := &replicaSetConfig{ID: "mongodb", Members: tt.members}
+			changed := updateReplicaSetConfig(testParams, config)
+			if changed != tt.wantChanged {
+				t.Errorf("got changed %v, want %v", changed, tt.wantChanged)
+			}
+			var votes []int
+			for _, member := range config.Members {
+				votes = append(votes, member.Votes)
+				if member.Votes == 0 && member.Priority != 0 {
+					t.Errorf("member %s has no votes but priority %v", member.Host, member.Priority)
+				}
+			}
+			if len(votes) != len(tt.wantVotes) {
+				t.Fatalf("got votes %v, want %v", votes, tt.wantVotes)
+			}
+			for i := range votes {
+				if votes[i] != tt.wantVotes[i] {
+					t.Errorf("got votes %v, want %v", votes, tt.wantVotes)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestApplyMemberOverrideKeepTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  map[string]string
+		override MemberOverride
+		want     map[string]string
+	}{
+		{name: "zone is resolved", current: map[string]string{"zone": "a"}, override: MemberOverride{Tags: map[string]string{"zone": "b"}}, want: map[string]string{"zone": "b"}},
+		{name: "zone is not resolved", current: map[string]string{"zone": "a", "old": "x"}, override: MemberOverride{Tags: map[string]string{"nodeType": "cheap"}, KeepTags: []string{"zone"}}, want: map[string]string{"zone": "a", "nodeType": "cheap"}},
+		{name: "zone was never set", current: map[string]string{}, override: MemberOverride{KeepTags: []string{"zone"}}, want: map[string]string{}},
+		{name: "zone is dropped", current: map[string]string{"zone": "a"}, override: MemberOverride{}, want: map[string]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			overrideTags := map[string]string{}
+			for key, value := range tt.override.Tags {
+				overrideTags[key] = value
+			}
+			member := replicaSetConfigMember{Tags: tt.current}
+			applyMemberOverride(&member, tt.override)
+			if len(member.Tags) != len(tt.want) {
+				t.Fatalf("got tags %v, want %v", member.Tags, tt.want)
+			}
+			for key, value := range tt.want {
+				if member.Tags[key] != value {
+					t.Errorf("got tags %v, want %v", member.Tags, tt.want)
+				}
+			}
+			if len(tt.override.Tags) != len(overrideTags) {
+				t.Errorf("override tags were modified: %v", tt.override.Tags)
+			}
+		})
+	}
+}
+
+func TestUpdateReplicaSetConfigUnmanagedMembers(t *testing.T) {
+	one := int32(1)
+	params := MongoDBParameters{Name: "mongodb", Namespace: "default", ClusterNodes: &one}
+	local := replicaSetConfigMember{ID: 0, Host: GetMongoNodeInfo(params, 0), Priority: 1, Votes: 1, Tags: map[string]string{}}
+	declared := ExternalMember{Host: "mongodb-cluster-0.dr.example.com:27017", Tags: map[string]string{"site": "dr"}}
+	tests := []struct {
+		name      string
+		external  []ExternalMember
+		members   []replicaSetConfigMember
+		wantHosts []string
+	}{
+		{
+			name:      "declared external member is tagged",
+			external:  []ExternalMember{declared},
+			members:   []replicaSetConfigMember{local, {ID: 1, Host: declared.Host, Tags: map[string]string{"site": "dr"}}},
+			wantHosts: []string{local.Host, declared.Host},
+		},
+		{
+			name:      "undeclared operator external member is removed",
+			members:   []replicaSetConfigMember{local, {ID: 1, Host: declared.Host, Tags: map[string]string{externalMemberTag: "true"}}},
+			wantHosts: []string{local.Host},
+		},
+		{
+			name:      "unknown member is kept",
+			members:   []replicaSetConfigMember{local, {ID: 1, Host: "legacy.example.com:27017", Priority: 1, Votes: 1, Tags: map[string]string{}}},
+			wantHosts: []string{local.Host, "legacy.example.com:27017"},
+		},
+		{
+			name:      "member of a previous domain is kept",
+			members:   []replicaSetConfigMember{{ID: 0, Host: "mongodb-cluster-0.old.example.com:27017", Priority: 1, Votes: 1, Tags: map[string]string{}}, local},
+			wantHosts: []string{"mongodb-cluster-0.old.example.com:27017", local.Host},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testParams := params
+			testParams.ExternalMembers = tt.external
+			config := &replicaSetConfig{ID: "mongodb", Members: tt.members}
+			updateReplicaSetConfig(testParams, config)
+			var hosts []string
+			for _, member := range config.Members {
+				hosts = append(hosts, member.Host)
+				if member.Host == declared.Host && member.Tags[externalMemberTag] == "" {
+					t.Errorf("external member %s is not tagged: %v", member.Host, member.Tags)
+				}
+			}
+			if strings.Join(hosts, ",") != strings.Join(tt.wantHosts, ",") {
+				t.Errorf("got members %v, want %v", hosts, tt.wantHosts)
+			}
+		})
+	}
+}
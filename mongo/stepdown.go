@@ -0,0 +1,21 @@
+package mongogo
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// StepDownMongoPrimary is a method to make the primary of MongoDB cluster step down once a secondary caught up with its writes
+func StepDownMongoPrimary(params MongoDBParameters, stepDownSecs int, catchUpSecs int) error {
+	client := initiateMongoClusterClient(params)
+	defer discconnectMongoClient(client)
+	command := bson.D{{Key: "replSetStepDown", Value: stepDownSecs}, {Key: "secondaryCatchUpPeriodSecs", Value: catchUpSecs}}
+	err := client.Database(dbName).RunCommand(context.Background(), command).Err()
+	// older MongoDB versions close all the connections when the primary steps down
+	if err != nil && !mongo.IsNetworkError(err) {
+		observeCommandError(params, err)
+		return err
+	}
+	return nil
+}